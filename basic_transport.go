@@ -24,7 +24,9 @@ func (bt *BasicTransport) addHeaders(req *http.Request) *http.Request {
 	req = cloneRequest(req)
 	req.Header.Set("Authorization", "Basic "+bt.encodedAPIKey)
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", getUserAgent())
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", getUserAgent())
+	}
 	return req
 }
 
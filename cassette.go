@@ -0,0 +1,347 @@
+package wakatime
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrInteractionNotFound is returned when replayed request is not in the
+// cassette
+var ErrInteractionNotFound = errors.New("interaction not found in cassette")
+
+// RecorderMode selects whether Recorder records or replays the interactions
+type RecorderMode int
+
+// Recorder modes
+const (
+	// ModeReplay serves the responses from the cassette file
+	ModeReplay RecorderMode = iota
+	// ModeRecord sends the requests and stores the responses in the cassette
+	ModeRecord
+)
+
+// cassetteSecrets are the form, query and JSON fields scrubbed from the
+// recorded interactions
+var cassetteSecrets = []string{"access_token", "api_key", "client_secret", "code", "refresh_token"}
+
+// ErrorReporter is notified of unmatched requests, satisfied by *testing.T
+type ErrorReporter interface {
+	Errorf(format string, args ...interface{})
+}
+
+// CassetteRequest is the recorded request. Credentials are scrubbed.
+type CassetteRequest struct {
+	Method string          `json:"method"`
+	URL    string          `json:"url"`
+	Header http.Header     `json:"header,omitempty"`
+	JSON   json.RawMessage `json:"json,omitempty"`
+	Body   string          `json:"body,omitempty"`
+}
+
+// CassetteResponse is the recorded response. JSON bodies are stored as JSON
+// to keep the cassette readable, other bodies as string.
+type CassetteResponse struct {
+	StatusCode int             `json:"status_code"`
+	Header     http.Header     `json:"header,omitempty"`
+	JSON       json.RawMessage `json:"json,omitempty"`
+	Body       string          `json:"body,omitempty"`
+}
+
+// Interaction is single request and its response
+type Interaction struct {
+	Request  CassetteRequest  `json:"request"`
+	Response CassetteResponse `json:"response"`
+}
+
+// Cassette is the content of the cassette file
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Recorder implements http.RoundTripper which records the interactions to a
+// cassette file or replays them from it. Requests are matched on the method,
+// path and query, each recorded interaction is replayed once in order.
+type Recorder struct {
+	// Transport sends the requests in ModeRecord
+	Transport http.RoundTripper
+	// Reporter is notified of unmatched requests in addition to the returned
+	// error
+	Reporter ErrorReporter
+	Path     string
+	Mode     RecorderMode
+	// Scrub is called with each recorded interaction after the known
+	// credentials were redacted, to remove other sensitive data
+	Scrub    func(*Interaction)
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+// NewRecorder creates new Recorder for the cassette at path. In ModeReplay the
+// cassette is loaded from the file.
+func NewRecorder(path string, mode RecorderMode) (*Recorder, error) {
+	r := &Recorder{
+		Transport: http.DefaultTransport,
+		Path:      path,
+		Mode:      mode,
+	}
+	if mode == ModeRecord {
+		return r, nil
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(content, &r.cassette); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	r.used = make([]bool, len(r.cassette.Interactions))
+	return r, nil
+}
+
+// RoundTrip implements the http.RoundTripper method
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	if r.Mode == ModeRecord {
+		return r.record(req)
+	}
+	return r.replay(req)
+}
+
+func (r *Recorder) record(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		if reqBody, err = ioutil.ReadAll(body); err != nil {
+			return nil, err
+		}
+		body.Close()
+	}
+	resp, err := r.Transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+	i := Interaction{
+		Request: CassetteRequest{
+			Method: req.Method,
+			URL:    cassetteURL(req.URL),
+			Header: RedactHeader(req.Header),
+		},
+		Response: CassetteResponse{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header.Clone(),
+		},
+	}
+	if i.Request.Header.Get("Cookie") != "" {
+		i.Request.Header.Set("Cookie", redacted)
+	}
+	i.Request.JSON, i.Request.Body = cassetteBody(scrubBody(reqBody, req.Header))
+	i.Response.JSON, i.Response.Body = cassetteBody(scrubBody(respBody, resp.Header))
+	// the body is re-indented in the cassette so its length changes
+	i.Response.Header.Del("Content-Length")
+	i.Response.Header.Del("Set-Cookie")
+	if r.Scrub != nil {
+		r.Scrub(&i)
+	}
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, i)
+	r.mu.Unlock()
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for n, i := range r.cassette.Interactions {
+		if r.used[n] || !matchInteraction(i.Request, req) {
+			continue
+		}
+		r.used[n] = true
+		body := []byte(i.Response.Body)
+		if len(i.Response.JSON) > 0 {
+			body = i.Response.JSON
+		}
+		header := i.Response.Header.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", i.Response.StatusCode, http.StatusText(i.Response.StatusCode)),
+			StatusCode:    i.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          ioutil.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+	err := fmt.Errorf("%s %s: %w: %s", req.Method, RedactURL(req.URL), ErrInteractionNotFound, r.Path)
+	if r.Reporter != nil {
+		r.Reporter.Errorf("%v", err)
+	}
+	return nil, err
+}
+
+// Unused returns the recorded interactions which were not replayed
+func (r *Recorder) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result []Interaction
+	for n, used := range r.used {
+		if !used {
+			result = append(result, r.cassette.Interactions[n])
+		}
+	}
+	return result
+}
+
+// Save writes the recorded interactions to the cassette file. It does nothing
+// in ModeReplay.
+func (r *Recorder) Save() error {
+	if r.Mode != ModeRecord {
+		return nil
+	}
+	// keep the query separators readable instead of \u0026
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	r.mu.Lock()
+	err := enc.Encode(r.cassette)
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(r.Path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.Path, buf.Bytes(), 0644)
+}
+
+// matchInteraction compares the method, path and query of the requests. The
+// recorded query has the credentials redacted, so they are redacted in the
+// compared request too.
+func matchInteraction(recorded CassetteRequest, req *http.Request) bool {
+	if recorded.Method != req.Method {
+		return false
+	}
+	u, err := url.Parse(recorded.URL)
+	if err != nil {
+		return false
+	}
+	actual, err := url.Parse(cassetteURL(req.URL))
+	if err != nil {
+		return false
+	}
+	return u.Path == actual.Path && u.Query().Encode() == actual.Query().Encode()
+}
+
+// cassetteURL returns the URL redacted like RedactURL with the credential
+// query fields also replaced
+func cassetteURL(u *url.URL) string {
+	r, err := url.Parse(RedactURL(u))
+	if err != nil {
+		return RedactURL(u)
+	}
+	if q := r.Query(); scrubValues(q) {
+		r.RawQuery = q.Encode()
+	}
+	return r.String()
+}
+
+// scrubValues redacts the credential fields and reports whether any was found
+func scrubValues(v url.Values) bool {
+	found := false
+	for _, k := range cassetteSecrets {
+		if _, ok := v[k]; ok {
+			v.Set(k, redacted)
+			found = true
+		}
+	}
+	return found
+}
+
+// scrubJSON redacts the credential fields at any depth and reports whether any
+// was found
+func scrubJSON(v interface{}) bool {
+	found := false
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, child := range t {
+			secret := false
+			for _, s := range cassetteSecrets {
+				if k == s {
+					secret = true
+					break
+				}
+			}
+			if secret {
+				t[k] = redacted
+				found = true
+			} else if scrubJSON(child) {
+				found = true
+			}
+		}
+	case []interface{}:
+		for _, child := range t {
+			if scrubJSON(child) {
+				found = true
+			}
+		}
+	}
+	return found
+}
+
+// scrubBody redacts the credential fields from JSON and form encoded bodies.
+// Bodies without credentials are returned unchanged.
+func scrubBody(body []byte, h http.Header) []byte {
+	if len(body) == 0 {
+		return body
+	}
+	if json.Valid(body) {
+		var v interface{}
+		if err := json.Unmarshal(body, &v); err == nil && scrubJSON(v) {
+			if scrubbed, err := json.Marshal(v); err == nil {
+				return scrubbed
+			}
+		}
+		return body
+	}
+	mt, _, _ := mime.ParseMediaType(h.Get("Content-Type"))
+	if mt == "application/x-www-form-urlencoded" || mt == "text/plain" {
+		if v, err := url.ParseQuery(string(body)); err == nil && scrubValues(v) {
+			return []byte(v.Encode())
+		}
+	}
+	return body
+}
+
+// cassetteBody returns body as JSON when it is valid JSON, otherwise as string
+func cassetteBody(body []byte) (json.RawMessage, string) {
+	if len(body) == 0 {
+		return nil, ""
+	}
+	if json.Valid(body) {
+		return json.RawMessage(body), ""
+	}
+	return nil, string(body)
+}
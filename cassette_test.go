@@ -0,0 +1,220 @@
+package wakatime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// newCassette replays the cassette from testdata/cassettes and fails the test on
+// unmatched requests
+func newCassette(t *testing.T, name string) *Recorder {
+	r, err := NewRecorder(filepath.Join("testdata", "cassettes", name+".json"), ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Reporter = t
+	return r
+}
+
+// RecordingReporter collects the reported errors
+type RecordingReporter struct {
+	errors []string
+}
+
+func (rr *RecordingReporter) Errorf(format string, args ...interface{}) {
+	rr.errors = append(rr.errors, fmt.Sprintf(format, args...))
+}
+
+func TestRecorder(t *testing.T) {
+	Convey("Given recorder in record mode", t, func() {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Set-Cookie", "session=secret")
+			switch r.URL.Path {
+			case "/api/v1/users/current":
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, users)
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		defer ts.Close()
+		dir, err := ioutil.TempDir("", "wakatime")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "cassettes", "users.json")
+		r, err := NewRecorder(path, ModeRecord)
+		So(err, ShouldBeNil)
+		bt := NewBasicTransport("waka_secret")
+		bt.Transport = r
+		wt := New(bt, WithBaseURL(ts.URL+"/api/v1"))
+		u, err := wt.Users(CurrentUser)
+		So(err, ShouldBeNil)
+		So(u.Data.Username, ShouldEqual, "aquilax")
+		_, err = wt.Users("missing")
+		So(IsNotFound(err), ShouldBeTrue)
+		So(r.Save(), ShouldBeNil)
+		content, err := ioutil.ReadFile(path)
+		So(err, ShouldBeNil)
+		Convey("Cassette must be readable and scrubbed", func() {
+			So(string(content), ShouldContainSubstring, `"username": "aquilax"`)
+			So(string(content), ShouldContainSubstring, `"body": "404 page not found\n"`)
+			So(string(content), ShouldContainSubstring, "Basic REDACTED")
+			So(string(content), ShouldNotContainSubstring, NewBasicTransport("waka_secret").encodedAPIKey)
+			So(string(content), ShouldNotContainSubstring, "session=secret")
+		})
+		Convey("Recorded interactions must be replayed", func() {
+			rr := &RecordingReporter{}
+			r, err := NewRecorder(path, ModeReplay)
+			So(err, ShouldBeNil)
+			r.Reporter = rr
+			wt := New(r, WithBaseURL("https://wakatime.com/api/v1"))
+			u, err := wt.Users(CurrentUser)
+			So(err, ShouldBeNil)
+			So(u.Data.Username, ShouldEqual, "aquilax")
+			So(r.Unused(), ShouldHaveLength, 1)
+			_, err = wt.Users("missing")
+			So(IsNotFound(err), ShouldBeTrue)
+			So(r.Unused(), ShouldBeEmpty)
+			So(rr.errors, ShouldBeEmpty)
+			Convey("Replayed interaction must not match again", func() {
+				_, err := wt.Users(CurrentUser)
+				So(errors.Is(err, ErrInteractionNotFound), ShouldBeTrue)
+				So(rr.errors, ShouldHaveLength, 1)
+				So(rr.errors[0], ShouldStartWith, "GET https://wakatime.com/api/v1/users/current: interaction not found in cassette")
+			})
+		})
+	})
+	Convey("Given cassette", t, func() {
+		rr := &RecordingReporter{}
+		r := newCassette(t, "heartbeats")
+		r.Reporter = rr
+		wt := New(r)
+		Convey("Request with different query must not match", func() {
+			_, err := wt.GetHartbeats(CurrentUser, time.Date(2015, 6, 3, 0, 0, 0, 0, time.UTC))
+			So(errors.Is(err, ErrInteractionNotFound), ShouldBeTrue)
+			So(rr.errors, ShouldHaveLength, 1)
+		})
+		Convey("Request with different path must not match", func() {
+			_, err := wt.Users(CurrentUser)
+			So(errors.Is(err, ErrInteractionNotFound), ShouldBeTrue)
+		})
+		Convey("Request with different method must not match", func() {
+			_, err := wt.SendHeartbeat(context.Background(), CurrentUser, HeartbeatItem{})
+			So(errors.Is(err, ErrInteractionNotFound), ShouldBeTrue)
+		})
+	})
+	Convey("Given recorded request with query", t, func() {
+		dir, err := ioutil.TempDir("", "wakatime")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "summaries.json")
+		r, err := NewRecorder(path, ModeRecord)
+		So(err, ShouldBeNil)
+		r.Transport = NewDummyTransport(summaries)
+		_, err = New(r).SummariesWithOptions(context.Background(), CurrentUser, &SummariesOptions{Range: RangeToday, Project: "go-wakatime"})
+		So(err, ShouldBeNil)
+		So(r.Save(), ShouldBeNil)
+		content, err := ioutil.ReadFile(path)
+		So(err, ShouldBeNil)
+		Convey("Query must stay readable", func() {
+			So(string(content), ShouldContainSubstring, "?project=go-wakatime&range=Today")
+		})
+	})
+	Convey("Given recorded OAuth exchange", t, func() {
+		fs := &FakeTokenServer{}
+		ts := httptest.NewServer(fs)
+		defer ts.Close()
+		dir, err := ioutil.TempDir("", "wakatime")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "oauth.json")
+		r, err := NewRecorder(path, ModeRecord)
+		So(err, ShouldBeNil)
+		r.Transport = http.DefaultTransport
+		c := newTestOAuthConfig(ts.URL)
+		c.HTTPClient = &http.Client{Transport: r}
+		record := func() string {
+			tok, err := c.Exchange(context.Background(), "code")
+			So(err, ShouldBeNil)
+			_, err = c.Refresh(context.Background(), tok.RefreshToken)
+			So(err, ShouldBeNil)
+			So(r.Save(), ShouldBeNil)
+			content, err := ioutil.ReadFile(path)
+			So(err, ShouldBeNil)
+			return string(content)
+		}
+		Convey("JSON token responses must be scrubbed", func() {
+			content := record()
+			So(content, ShouldContainSubstring, `"access_token": "REDACTED"`)
+			So(content, ShouldContainSubstring, "client_secret=REDACTED")
+			So(content, ShouldContainSubstring, "grant_type=authorization_code")
+			for _, secret := range []string{"access1", "refresh1", "access2", "refresh2", "client_secret=secret", "code=code"} {
+				So(content, ShouldNotContainSubstring, secret)
+			}
+		})
+		Convey("Form encoded token responses must be scrubbed", func() {
+			fs.form = true
+			content := record()
+			So(content, ShouldContainSubstring, "access_token=REDACTED")
+			So(content, ShouldContainSubstring, "scope=read_stats")
+			for _, secret := range []string{"access1", "refresh1", "access2", "refresh2"} {
+				So(content, ShouldNotContainSubstring, secret)
+			}
+		})
+		Convey("Recorded exchange must be replayed", func() {
+			record()
+			rr := &RecordingReporter{}
+			r, err := NewRecorder(path, ModeReplay)
+			So(err, ShouldBeNil)
+			r.Reporter = rr
+			c.HTTPClient = &http.Client{Transport: r}
+			tok, err := c.Exchange(context.Background(), "code")
+			So(err, ShouldBeNil)
+			So(tok.AccessToken, ShouldEqual, "REDACTED")
+			So(rr.errors, ShouldBeEmpty)
+		})
+	})
+	Convey("Given recorded request with cookie", t, func() {
+		dir, err := ioutil.TempDir("", "wakatime")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "users.json")
+		r, err := NewRecorder(path, ModeRecord)
+		So(err, ShouldBeNil)
+		r.Transport = NewDummyTransport(users)
+		r.Scrub = func(i *Interaction) {
+			i.Request.Header.Del("X-Session")
+		}
+		req, err := http.NewRequest(http.MethodGet, "https://wakatime.com/api/v1/users/current?api_key=key&code=abc", nil)
+		So(err, ShouldBeNil)
+		req.Header.Set("Cookie", "session=secret")
+		req.Header.Set("X-Session", "private")
+		resp, err := r.RoundTrip(req)
+		So(err, ShouldBeNil)
+		resp.Body.Close()
+		So(r.Save(), ShouldBeNil)
+		content, err := ioutil.ReadFile(path)
+		So(err, ShouldBeNil)
+		Convey("Cookie, query credentials and scrubbed headers must not be stored", func() {
+			So(string(content), ShouldNotContainSubstring, "session=secret")
+			So(string(content), ShouldNotContainSubstring, "code=abc")
+			So(string(content), ShouldNotContainSubstring, "private")
+			So(string(content), ShouldContainSubstring, "api_key=REDACTED&code=REDACTED")
+			So(string(content), ShouldContainSubstring, `"Cookie": [`)
+		})
+	})
+	Convey("Given missing cassette", t, func() {
+		_, err := NewRecorder(filepath.Join("testdata", "cassettes", "missing.json"), ModeReplay)
+		So(os.IsNotExist(err), ShouldBeTrue)
+	})
+}
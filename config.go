@@ -0,0 +1,239 @@
+package wakatime
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConfigFileName is the name of the WakaTime configuration file
+const ConfigFileName = ".wakatime.cfg"
+
+// ConfigHomeEnv is the environment variable overriding the directory of the
+// configuration file
+const ConfigHomeEnv = "WAKATIME_HOME"
+
+const configSection = "settings"
+
+// ErrNoAPIKey is returned when the configuration has no API key
+var ErrNoAPIKey = errors.New("api_key and api_key_vault_cmd are missing from the configuration")
+
+// Config contains the [settings] section of the WakaTime configuration file
+type Config struct {
+	APIKey string
+	// APIKeyVaultCmd is the command printing the API key, used when APIKey is
+	// empty
+	APIKeyVaultCmd string
+	APIURL         string
+	Proxy          string
+	Timeout        time.Duration
+	// HideFileNames contains the patterns of the entities which are reported
+	// obfuscated. The value true is converted to a pattern matching everything.
+	HideFileNames []string
+	Exclude       []string
+	Include       []string
+	NoSSLVerify   bool
+}
+
+// ConfigPath returns the path of the configuration file in WAKATIME_HOME or the
+// home directory of the user
+func ConfigPath() (string, error) {
+	dir := os.Getenv(ConfigHomeEnv)
+	if dir == "" {
+		var err error
+		if dir, err = os.UserHomeDir(); err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(dir, ConfigFileName), nil
+}
+
+// LoadConfig reads the configuration file from its standard location
+func LoadConfig() (*Config, error) {
+	path, err := ConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	return ReadConfig(path)
+}
+
+// ReadConfig reads the configuration file at path
+func ReadConfig(path string) (*Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseConfig(f)
+}
+
+// ParseConfig parses INI formatted configuration. Values can continue on the
+// following indented lines, which is used for the lists of patterns.
+func ParseConfig(r io.Reader) (*Config, error) {
+	settings, err := parseINISection(r, configSection)
+	if err != nil {
+		return nil, err
+	}
+	c := &Config{
+		APIKey:         settings["api_key"],
+		APIKeyVaultCmd: settings["api_key_vault_cmd"],
+		APIURL:         settings["api_url"],
+		Proxy:          settings["proxy"],
+		Exclude:        splitPatterns(settings["exclude"]),
+		Include:        splitPatterns(settings["include"]),
+	}
+	if v := settings["timeout"]; v != "" {
+		sec, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout %q: %w", v, err)
+		}
+		c.Timeout = time.Duration(sec) * time.Second
+	}
+	if c.NoSSLVerify, err = parseConfigBool(settings, "no_ssl_verify"); err != nil {
+		return nil, err
+	}
+	hide, ok := settings["hide_file_names"]
+	if !ok {
+		hide = settings["hidefilenames"]
+	}
+	switch strings.ToLower(hide) {
+	case "", "false":
+	case "true":
+		c.HideFileNames = []string{".*"}
+	default:
+		c.HideFileNames = splitPatterns(hide)
+	}
+	return c, nil
+}
+
+// parseINISection returns the keys of section
+func parseINISection(r io.Reader, section string) (map[string]string, error) {
+	values := map[string]string{}
+	current := ""
+	key := ""
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		raw := s.Text()
+		line := strings.TrimSpace(raw)
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if line[0] == '[' && line[len(line)-1] == ']' {
+			current = strings.TrimSpace(line[1 : len(line)-1])
+			key = ""
+			continue
+		}
+		if current != section {
+			continue
+		}
+		if key != "" && (raw[0] == ' ' || raw[0] == '\t') {
+			values[key] = strings.TrimLeft(values[key]+"\n"+line, "\n")
+			continue
+		}
+		i := strings.IndexAny(line, "=:")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid line %d: %q", n, raw)
+		}
+		key = strings.ToLower(strings.TrimSpace(line[:i]))
+		values[key] = strings.TrimSpace(line[i+1:])
+	}
+	return values, s.Err()
+}
+
+func parseConfigBool(settings map[string]string, key string) (bool, error) {
+	v := settings[key]
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s %q: %w", key, v, err)
+	}
+	return b, nil
+}
+
+func splitPatterns(value string) []string {
+	var result []string
+	for _, p := range strings.Split(value, "\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// trimAPIURL removes the heartbeats endpoint which older configuration files
+// include in api_url, the way wakatime-cli does
+func trimAPIURL(apiURL string) string {
+	apiURL = strings.TrimSuffix(apiURL, "/")
+	apiURL = strings.TrimSuffix(apiURL, ".bulk")
+	apiURL = strings.TrimSuffix(apiURL, "/users/current/heartbeats")
+	apiURL = strings.TrimSuffix(apiURL, "/heartbeats")
+	apiURL = strings.TrimSuffix(apiURL, "/heartbeat")
+	return apiURL
+}
+
+// transport returns http.Transport with the proxy and TLS settings applied
+func (c *Config) transport() (*http.Transport, error) {
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	if c.Proxy != "" {
+		if strings.Contains(c.Proxy, `\`) {
+			return nil, fmt.Errorf("unsupported NTLM proxy %q", c.Proxy)
+		}
+		proxy, err := url.Parse(c.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy: %w", err)
+		}
+		tr.Proxy = http.ProxyURL(proxy)
+	}
+	if c.NoSSLVerify {
+		tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	return tr, nil
+}
+
+// authTransport returns the transport authenticating with the API key or the
+// output of the vault command
+func (c *Config) authTransport(tr http.RoundTripper) (http.RoundTripper, error) {
+	if c.APIKey != "" {
+		bt := NewBasicTransport(c.APIKey)
+		bt.Transport = tr
+		return bt, nil
+	}
+	if cmd := strings.Fields(c.APIKeyVaultCmd); len(cmd) > 0 {
+		ct := NewCredentialTransport(NewCommandCredential(0, cmd[0], cmd[1:]...), AuthBasic)
+		ct.Transport = tr
+		return ct, nil
+	}
+	return nil, ErrNoAPIKey
+}
+
+// NewFromConfig creates new WakaTime client authenticated with the API key from
+// the configuration. The options are applied after the configured ones.
+func NewFromConfig(c *Config, opts ...Option) (*WakaTime, error) {
+	tr, err := c.transport()
+	if err != nil {
+		return nil, err
+	}
+	rt, err := c.authTransport(tr)
+	if err != nil {
+		return nil, err
+	}
+	var configured []Option
+	if base := trimAPIURL(c.APIURL); base != "" {
+		configured = append(configured, WithBaseURL(base))
+	}
+	if c.Timeout > 0 {
+		configured = append(configured, WithTimeout(c.Timeout))
+	}
+	return New(rt, append(configured, opts...)...), nil
+}
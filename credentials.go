@@ -0,0 +1,256 @@
+package wakatime
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// redacted replaces credentials in logged requests
+const redacted = "REDACTED"
+
+// ErrNoCredential is returned when the provider has no credential
+var ErrNoCredential = errors.New("credential is empty")
+
+// CredentialProvider returns the credential used to authenticate a request. It
+// is consulted on every request, so rotated credentials are picked up without
+// restarting the client.
+type CredentialProvider interface {
+	Credential(ctx context.Context) (string, error)
+}
+
+// AuthScheme is the style of the Authorization header
+type AuthScheme int
+
+// Authorization header styles
+const (
+	// AuthBasic sends the base64 encoded API key as Basic Auth
+	AuthBasic AuthScheme = iota
+	// AuthBearer sends the credential as is as Bearer token
+	AuthBearer
+)
+
+// StaticCredential is a credential fixed at construction time
+type StaticCredential struct {
+	credential string
+}
+
+// NewStaticCredential creates new StaticCredential
+func NewStaticCredential(credential string) *StaticCredential {
+	return &StaticCredential{credential: credential}
+}
+
+// Credential implements the CredentialProvider method
+func (sc *StaticCredential) Credential(ctx context.Context) (string, error) {
+	if sc.credential == "" {
+		return "", ErrNoCredential
+	}
+	return sc.credential, nil
+}
+
+// String hides the credential when the provider is printed
+func (sc *StaticCredential) String() string {
+	return redacted
+}
+
+// EnvCredential reads the credential from the named environment variable
+type EnvCredential string
+
+// Credential implements the CredentialProvider method
+func (ec EnvCredential) Credential(ctx context.Context) (string, error) {
+	v := strings.TrimSpace(os.Getenv(string(ec)))
+	if v == "" {
+		return "", fmt.Errorf("%s: %w", string(ec), ErrNoCredential)
+	}
+	return v, nil
+}
+
+// FileCredential reads the credential from a file and reads it again when the
+// file's size or modification time changes
+type FileCredential struct {
+	Path       string
+	mu         sync.Mutex
+	size       int64
+	modTime    time.Time
+	credential string
+}
+
+// NewFileCredential creates new FileCredential for the file at path
+func NewFileCredential(path string) *FileCredential {
+	return &FileCredential{Path: path}
+}
+
+// Credential implements the CredentialProvider method
+func (fc *FileCredential) Credential(ctx context.Context) (string, error) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	fi, err := os.Stat(fc.Path)
+	if err != nil {
+		return "", err
+	}
+	if fc.credential == "" || fi.Size() != fc.size || !fi.ModTime().Equal(fc.modTime) {
+		content, err := ioutil.ReadFile(fc.Path)
+		if err != nil {
+			return "", err
+		}
+		fc.credential = strings.TrimSpace(string(content))
+		fc.size = fi.Size()
+		fc.modTime = fi.ModTime()
+	}
+	if fc.credential == "" {
+		return "", fmt.Errorf("%s: %w", fc.Path, ErrNoCredential)
+	}
+	return fc.credential, nil
+}
+
+// CommandCredential runs a command, like a password manager client, and uses
+// its trimmed output as the credential
+type CommandCredential struct {
+	Name string
+	Args []string
+	// TTL is how long the output is reused. Zero runs the command once.
+	TTL time.Duration
+	// Clock is used to expire the output, the system clock is used when nil
+	Clock      Clock
+	mu         sync.Mutex
+	fetched    time.Time
+	credential string
+}
+
+// NewCommandCredential creates new CommandCredential running name with args
+func NewCommandCredential(ttl time.Duration, name string, args ...string) *CommandCredential {
+	return &CommandCredential{
+		Name:  name,
+		Args:  args,
+		TTL:   ttl,
+		Clock: systemClock{},
+	}
+}
+
+// Credential implements the CredentialProvider method
+func (cc *CommandCredential) Credential(ctx context.Context) (string, error) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	now := cc.clock().Now()
+	if cc.credential != "" && (cc.TTL == 0 || now.Sub(cc.fetched) < cc.TTL) {
+		return cc.credential, nil
+	}
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, cc.Name, cc.Args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %w: %s", cc.Name, err, msg)
+		}
+		return "", fmt.Errorf("%s: %w", cc.Name, err)
+	}
+	credential := strings.TrimSpace(string(out))
+	if credential == "" {
+		return "", fmt.Errorf("%s: %w", cc.Name, ErrNoCredential)
+	}
+	cc.credential = credential
+	cc.fetched = now
+	return credential, nil
+}
+
+func (cc *CommandCredential) clock() Clock {
+	if cc.Clock == nil {
+		return systemClock{}
+	}
+	return cc.Clock
+}
+
+// CredentialTransport implements http.RoundTripper and authenticates each
+// request with the current credential of the provider
+type CredentialTransport struct {
+	Provider  CredentialProvider
+	Scheme    AuthScheme
+	Transport http.RoundTripper
+}
+
+// NewCredentialTransport creates new CredentialTransport
+func NewCredentialTransport(provider CredentialProvider, scheme AuthScheme) *CredentialTransport {
+	return &CredentialTransport{
+		Provider:  provider,
+		Scheme:    scheme,
+		Transport: http.DefaultTransport,
+	}
+}
+
+// RoundTrip implements the http.RoundTripper method
+func (ct *CredentialTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	credential, err := ct.Provider.Credential(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	req = cloneRequest(req)
+	switch ct.Scheme {
+	case AuthBearer:
+		req.Header.Set("Authorization", "Bearer "+credential)
+	default:
+		req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(credential)))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", getUserAgent())
+	}
+	return ct.Transport.RoundTrip(req)
+}
+
+// RedactHeader returns copy of the header with the credentials replaced, safe
+// to be logged
+func RedactHeader(h http.Header) http.Header {
+	result := make(http.Header, len(h))
+	for k, v := range h {
+		result[k] = append([]string(nil), v...)
+	}
+	for _, k := range []string{"Authorization", "Proxy-Authorization"} {
+		for i, v := range result[k] {
+			if i := strings.IndexByte(v, ' '); i > 0 {
+				v = v[:i+1] + redacted
+			} else {
+				v = redacted
+			}
+			result[k][i] = v
+		}
+	}
+	return result
+}
+
+// RedactURL returns the URL with the api_key query parameter and the user
+// password replaced, safe to be logged
+func RedactURL(u *url.URL) string {
+	r := *u
+	if r.User != nil {
+		if _, ok := r.User.Password(); ok {
+			r.User = url.UserPassword(r.User.Username(), redacted)
+		}
+	}
+	q := r.Query()
+	if q.Get("api_key") != "" {
+		q.Set("api_key", redacted)
+		r.RawQuery = q.Encode()
+	}
+	return r.String()
+}
+
+// RedactRequest returns shallow copy of the request with the credentials
+// replaced in the URL and the headers, safe to be logged
+func RedactRequest(req *http.Request) *http.Request {
+	r := req.Clone(req.Context())
+	r.Header = RedactHeader(req.Header)
+	u, _ := url.Parse(RedactURL(req.URL))
+	r.URL = u
+	return r
+}
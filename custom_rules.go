@@ -0,0 +1,238 @@
+package wakatime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// CustomRuleCondition contains single condition matching the heartbeats
+type CustomRuleCondition struct {
+	// Field is the heartbeat attribute, e.g. entity, project or language
+	Field string `json:"field"`
+	// Operator is the comparison, e.g. contains, equals or starts_with
+	Operator string `json:"operator"`
+	Value    string `json:"value"`
+}
+
+// CustomRule changes the heartbeats matching all conditions
+type CustomRule struct {
+	ID         string                `json:"id,omitempty"`
+	Conditions []CustomRuleCondition `json:"conditions"`
+	// Action is the change applied, e.g. change_project or change_category
+	Action      string     `json:"action"`
+	ActionValue string     `json:"action_value"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+}
+
+// CustomRules contains the user's custom rules
+type CustomRules struct {
+	Data []CustomRule
+}
+
+// customRuleResponse is the body of the response for a single custom rule
+type customRuleResponse struct {
+	Data CustomRule
+}
+
+// CustomRuleUpdate contains the current and the desired version of a rule
+type CustomRuleUpdate struct {
+	Current CustomRule
+	Desired CustomRule
+}
+
+// CustomRulesPlan contains the changes needed to reach the desired rules
+type CustomRulesPlan struct {
+	Create []CustomRule
+	Update []CustomRuleUpdate
+	Delete []CustomRule
+}
+
+// String returns the rule in human readable form
+func (r CustomRule) String() string {
+	conds := make([]string, len(r.Conditions))
+	for i, c := range r.Conditions {
+		conds[i] = fmt.Sprintf("%s %s %q", c.Field, c.Operator, c.Value)
+	}
+	return fmt.Sprintf("if %s then %s %q", strings.Join(conds, " and "), r.Action, r.ActionValue)
+}
+
+// sameRule reports whether the rules have the same conditions and action
+func sameRule(a, b CustomRule) bool {
+	if a.Action != b.Action || a.ActionValue != b.ActionValue || len(a.Conditions) != len(b.Conditions) {
+		return false
+	}
+	for i := range a.Conditions {
+		if a.Conditions[i] != b.Conditions[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// IsEmpty reports whether the plan has no changes
+func (p *CustomRulesPlan) IsEmpty() bool {
+	return len(p.Create) == 0 && len(p.Update) == 0 && len(p.Delete) == 0
+}
+
+// String returns the plan as a diff, one change per line
+func (p *CustomRulesPlan) String() string {
+	var sb strings.Builder
+	for _, r := range p.Create {
+		fmt.Fprintf(&sb, "+ %s\n", r)
+	}
+	for _, u := range p.Update {
+		fmt.Fprintf(&sb, "~ %s\n  -> %s\n", u.Current, u.Desired)
+	}
+	for _, r := range p.Delete {
+		fmt.Fprintf(&sb, "- %s\n", r)
+	}
+	return sb.String()
+}
+
+// PlanCustomRules computes the changes needed to turn the current rules into
+// the desired ones. Desired rules with ID are matched to the current rule with
+// the same ID, the others to an identical current rule.
+func PlanCustomRules(current, desired []CustomRule) *CustomRulesPlan {
+	p := &CustomRulesPlan{}
+	matched := make([]bool, len(current))
+	var unmatched []CustomRule
+	for _, d := range desired {
+		found := false
+		for i, c := range current {
+			if !matched[i] && d.ID != "" && c.ID == d.ID {
+				matched[i], found = true, true
+				if !sameRule(c, d) {
+					p.Update = append(p.Update, CustomRuleUpdate{Current: c, Desired: d})
+				}
+				break
+			}
+		}
+		if !found {
+			unmatched = append(unmatched, d)
+		}
+	}
+	for _, d := range unmatched {
+		found := false
+		for i, c := range current {
+			if !matched[i] && sameRule(c, d) {
+				matched[i], found = true, true
+				break
+			}
+		}
+		if !found {
+			p.Create = append(p.Create, d)
+		}
+	}
+	for i, c := range current {
+		if !matched[i] {
+			p.Delete = append(p.Delete, c)
+		}
+	}
+	return p
+}
+
+// CustomRules fetches the user's custom rules
+func (wt *WakaTime) CustomRules(ctx context.Context, user string) (*CustomRules, error) {
+	var err error
+	var u *url.URL
+	if u, err = wt.apiURL("users", user, "custom_rules"); err != nil {
+		return nil, err
+	}
+	var content []byte
+	if content, err = wt.fetchURL(ctx, u.String()); err != nil {
+		return nil, err
+	}
+	var cr CustomRules
+	if err = json.Unmarshal(content, &cr); err != nil {
+		return nil, err
+	}
+	return &cr, nil
+}
+
+// CreateCustomRule creates new custom rule for the user
+func (wt *WakaTime) CreateCustomRule(ctx context.Context, user string, rule CustomRule) (*CustomRule, error) {
+	var err error
+	var u *url.URL
+	if u, err = wt.apiURL("users", user, "custom_rules"); err != nil {
+		return nil, err
+	}
+	rule.ID = ""
+	return wt.sendCustomRule(ctx, http.MethodPost, u, rule)
+}
+
+// UpdateCustomRule replaces the user's custom rule with rule.ID
+func (wt *WakaTime) UpdateCustomRule(ctx context.Context, user string, rule CustomRule) (*CustomRule, error) {
+	var err error
+	var u *url.URL
+	if u, err = wt.apiURL("users", user, "custom_rules", rule.ID); err != nil {
+		return nil, err
+	}
+	return wt.sendCustomRule(ctx, http.MethodPut, u, rule)
+}
+
+// DeleteCustomRule deletes the user's custom rule by id
+func (wt *WakaTime) DeleteCustomRule(ctx context.Context, user, id string) error {
+	var err error
+	var u *url.URL
+	if u, err = wt.apiURL("users", user, "custom_rules", id); err != nil {
+		return err
+	}
+	_, err = wt.sendURL(ctx, http.MethodDelete, u.String(), nil)
+	return err
+}
+
+// ApplyCustomRules makes the user's custom rules match the desired ones. It
+// returns the planned changes and the ones which were applied, which differ
+// when a request fails. Rules are created and updated before the obsolete ones
+// are deleted so a failure never leaves the user with fewer rules. With dryRun
+// the plan is only computed against the current server state and nothing is
+// changed.
+func (wt *WakaTime) ApplyCustomRules(ctx context.Context, user string, desired []CustomRule, dryRun bool) (planned, applied *CustomRulesPlan, err error) {
+	current, err := wt.CustomRules(ctx, user)
+	if err != nil {
+		return nil, nil, err
+	}
+	planned = PlanCustomRules(current.Data, desired)
+	applied = &CustomRulesPlan{}
+	if dryRun {
+		return planned, applied, nil
+	}
+	for _, r := range planned.Create {
+		var created *CustomRule
+		if created, err = wt.CreateCustomRule(ctx, user, r); err != nil {
+			return planned, applied, err
+		}
+		r.ID = created.ID
+		applied.Create = append(applied.Create, r)
+	}
+	for _, up := range planned.Update {
+		if _, err = wt.UpdateCustomRule(ctx, user, up.Desired); err != nil {
+			return planned, applied, err
+		}
+		applied.Update = append(applied.Update, up)
+	}
+	for _, r := range planned.Delete {
+		if err = wt.DeleteCustomRule(ctx, user, r.ID); err != nil {
+			return planned, applied, err
+		}
+		applied.Delete = append(applied.Delete, r)
+	}
+	return planned, applied, nil
+}
+
+func (wt *WakaTime) sendCustomRule(ctx context.Context, method string, u *url.URL, rule CustomRule) (*CustomRule, error) {
+	content, err := wt.sendURL(ctx, method, u.String(), rule)
+	if err != nil {
+		return nil, err
+	}
+	var cr customRuleResponse
+	if err = json.Unmarshal(content, &cr); err != nil {
+		return nil, err
+	}
+	return &cr.Data, nil
+}
@@ -0,0 +1,143 @@
+package wakatime
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const customRules = `{
+  "data": [
+    {
+      "action": "change_project",
+      "action_value": "go-wakatime",
+      "conditions": [{"field": "entity", "operator": "contains", "value": "/go-wakatime/"}],
+      "created_at": "2015-04-23T04:38:05Z",
+      "id": "r1"
+    },
+    {
+      "action": "change_category",
+      "action_value": "writing docs",
+      "conditions": [{"field": "language", "operator": "equals", "value": "Markdown"}],
+      "id": "r2"
+    },
+    {
+      "action": "change_project",
+      "action_value": "legacy",
+      "conditions": [{"field": "project", "operator": "starts_with", "value": "old-"}],
+      "id": "r3"
+    }
+  ]
+}`
+
+// RoutesTransport responds by method and path and records the requests. Routes
+// in failures respond with the given status code.
+type RoutesTransport struct {
+	routes   map[string]string
+	failures map[string]int
+	calls    []string
+}
+
+func (rt *RoutesTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := req.Method + " " + req.URL.Path
+	rt.calls = append(rt.calls, key)
+	content, ok := rt.routes[key]
+	statusCode := http.StatusOK
+	if !ok {
+		content = `{"data": {}}`
+		if req.Method == http.MethodPost {
+			statusCode = http.StatusCreated
+		}
+	}
+	if code, ok := rt.failures[key]; ok {
+		statusCode = code
+		content = `{"error": "failed"}`
+	}
+	return &http.Response{
+		StatusCode: statusCode,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(content)),
+	}, nil
+}
+
+func TestCustomRules(t *testing.T) {
+	docs := CustomRule{
+		Action:      "change_category",
+		ActionValue: "writing docs",
+		Conditions:  []CustomRuleCondition{{Field: "language", Operator: "equals", Value: "Markdown"}},
+	}
+	project := CustomRule{
+		ID:          "r1",
+		Action:      "change_project",
+		ActionValue: "wakatime-go",
+		Conditions:  []CustomRuleCondition{{Field: "entity", Operator: "contains", Value: "/go-wakatime/"}},
+	}
+	tests := CustomRule{
+		Action:      "change_category",
+		ActionValue: "writing tests",
+		Conditions:  []CustomRuleCondition{{Field: "entity", Operator: "ends_with", Value: "_test.go"}},
+	}
+	Convey("Given wakatime", t, func() {
+		rt := &RoutesTransport{routes: map[string]string{"GET /api/v1/users/current/custom_rules": customRules}}
+		wt := New(rt)
+		Convey("Custom rules JSON must be correctly parsed", func() {
+			cr, err := wt.CustomRules(context.Background(), CurrentUser)
+			So(err, ShouldBeNil)
+			So(len(cr.Data), ShouldEqual, 3)
+			So(cr.Data[0].ID, ShouldEqual, "r1")
+			So(cr.Data[0].Conditions[0].Operator, ShouldEqual, "contains")
+			So(cr.Data[0].String(), ShouldEqual, `if entity contains "/go-wakatime/" then change_project "go-wakatime"`)
+		})
+		Convey("Dry run must only plan the changes", func() {
+			p, applied, err := wt.ApplyCustomRules(context.Background(), CurrentUser, []CustomRule{docs, project, tests}, true)
+			So(err, ShouldBeNil)
+			So(applied.IsEmpty(), ShouldBeTrue)
+			So(rt.calls, ShouldResemble, []string{"GET /api/v1/users/current/custom_rules"})
+			So(p.Create, ShouldResemble, []CustomRule{tests})
+			So(len(p.Update), ShouldEqual, 1)
+			So(p.Update[0].Current.ActionValue, ShouldEqual, "go-wakatime")
+			So(p.Update[0].Desired.ActionValue, ShouldEqual, "wakatime-go")
+			So(len(p.Delete), ShouldEqual, 1)
+			So(p.Delete[0].ID, ShouldEqual, "r3")
+			So(p.String(), ShouldEqual, `+ if entity ends_with "_test.go" then change_category "writing tests"
+~ if entity contains "/go-wakatime/" then change_project "go-wakatime"
+  -> if entity contains "/go-wakatime/" then change_project "wakatime-go"
+- if project starts_with "old-" then change_project "legacy"
+`)
+		})
+		Convey("Apply must send the planned changes", func() {
+			p, applied, err := wt.ApplyCustomRules(context.Background(), CurrentUser, []CustomRule{docs, project, tests}, false)
+			So(err, ShouldBeNil)
+			So(p.IsEmpty(), ShouldBeFalse)
+			So(applied.String(), ShouldEqual, p.String())
+			So(rt.calls, ShouldResemble, []string{
+				"GET /api/v1/users/current/custom_rules",
+				"POST /api/v1/users/current/custom_rules",
+				"PUT /api/v1/users/current/custom_rules/r1",
+				"DELETE /api/v1/users/current/custom_rules/r3",
+			})
+		})
+		Convey("Failed apply must keep the old rules and report the applied steps", func() {
+			rt.failures = map[string]int{"PUT /api/v1/users/current/custom_rules/r1": http.StatusInternalServerError}
+			p, applied, err := wt.ApplyCustomRules(context.Background(), CurrentUser, []CustomRule{docs, project, tests}, false)
+			So(err, ShouldNotBeNil)
+			So(len(p.Delete), ShouldEqual, 1)
+			So(len(applied.Create), ShouldEqual, 1)
+			So(applied.Update, ShouldBeEmpty)
+			So(applied.Delete, ShouldBeEmpty)
+			So(rt.calls, ShouldResemble, []string{
+				"GET /api/v1/users/current/custom_rules",
+				"POST /api/v1/users/current/custom_rules",
+				"PUT /api/v1/users/current/custom_rules/r1",
+			})
+		})
+	})
+	Convey("Given identical rules", t, func() {
+		p := PlanCustomRules([]CustomRule{project}, []CustomRule{project})
+		So(p.IsEmpty(), ShouldBeTrue)
+		So(p.String(), ShouldBeEmpty)
+	})
+}
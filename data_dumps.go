@@ -0,0 +1,313 @@
+package wakatime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Data dump types
+const (
+	DataDumpHeartbeats = "heartbeats"
+	DataDumpDaily      = "daily"
+)
+
+// MinDataDumpPollInterval is the shortest interval WaitDataDump polls with
+const MinDataDumpPollInterval = time.Second
+
+// ErrDataDumpFailed is returned when waiting for a data dump which failed or
+// got stuck on the server
+var ErrDataDumpFailed = errors.New("data dump failed")
+
+// DataDumpData contains single data dump export
+type DataDumpData struct {
+	CreatedAt       time.Time `json:"created_at"`
+	DownloadURL     string    `json:"download_url"`
+	Expires         *time.Time
+	HasFailed       bool `json:"has_failed"`
+	ID              string
+	IsProcessing    bool    `json:"is_processing"`
+	IsStuck         bool    `json:"is_stuck"`
+	PercentComplete float64 `json:"percent_complete"`
+	Status          string
+	Type            string
+}
+
+// DataDumps contains the user's data dumps
+type DataDumps struct {
+	Data       []DataDumpData
+	Total      int
+	TotalPages int `json:"total_pages"`
+}
+
+// DataDump contains single data dump report
+type DataDump struct {
+	Data DataDumpData
+}
+
+// createDataDumpRequest is the body of the data dump request
+type createDataDumpRequest struct {
+	Type              string `json:"type"`
+	EmailWhenFinished bool   `json:"email_when_finished"`
+}
+
+// IsReady reports whether the data dump can be downloaded
+func (d *DataDumpData) IsReady() bool {
+	return !d.IsProcessing && d.DownloadURL != ""
+}
+
+// CreateDataDump requests new data dump export of the given type
+func (wt *WakaTime) CreateDataDump(ctx context.Context, user, dumpType string, emailWhenFinished bool) (*DataDump, error) {
+	var err error
+	var u *url.URL
+	if u, err = wt.apiURL("users", user, "data_dumps"); err != nil {
+		return nil, err
+	}
+	var content []byte
+	if content, err = wt.sendURL(ctx, http.MethodPost, u.String(), createDataDumpRequest{
+		Type:              dumpType,
+		EmailWhenFinished: emailWhenFinished,
+	}); err != nil {
+		return nil, err
+	}
+	var d DataDump
+	if err = json.Unmarshal(content, &d); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// DataDumps fetches the user's data dumps
+func (wt *WakaTime) DataDumps(ctx context.Context, user string) (*DataDumps, error) {
+	var err error
+	var u *url.URL
+	if u, err = wt.apiURL("users", user, "data_dumps"); err != nil {
+		return nil, err
+	}
+	var content []byte
+	if content, err = wt.fetchURL(ctx, u.String()); err != nil {
+		return nil, err
+	}
+	var d DataDumps
+	if err = json.Unmarshal(content, &d); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// WaitDataDump polls the user's data dumps every interval until the dump with
+// the given id is ready, has failed or the context is done. Intervals shorter
+// than MinDataDumpPollInterval are raised to it.
+func (wt *WakaTime) WaitDataDump(ctx context.Context, user, id string, interval time.Duration) (*DataDumpData, error) {
+	if interval < MinDataDumpPollInterval {
+		interval = MinDataDumpPollInterval
+	}
+	for {
+		dumps, err := wt.DataDumps(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		var dump *DataDumpData
+		for i := range dumps.Data {
+			if dumps.Data[i].ID == id {
+				dump = &dumps.Data[i]
+				break
+			}
+		}
+		switch {
+		case dump == nil:
+			return nil, fmt.Errorf("data dump %s not found", id)
+		case dump.HasFailed || dump.IsStuck:
+			return dump, fmt.Errorf("%w: %s", ErrDataDumpFailed, dump.Status)
+		case dump.IsReady():
+			return dump, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-wt.clock.After(interval):
+		}
+	}
+}
+
+// dumpState is the position of DumpDecoder in the dump
+type dumpState int
+
+const (
+	dumpBeforeDays dumpState = iota
+	dumpInDays
+	dumpInHeartbeats
+	dumpDone
+)
+
+// DumpDecoder reads heartbeats from a downloaded heartbeats data dump without
+// loading the whole file in memory
+type DumpDecoder struct {
+	dec       *json.Decoder
+	day       string
+	heartbeat HeartbeatItem
+	err       error
+	state     dumpState
+}
+
+// NewDumpDecoder creates new DumpDecoder reading from r
+func NewDumpDecoder(r io.Reader) *DumpDecoder {
+	return &DumpDecoder{dec: json.NewDecoder(r)}
+}
+
+// Next advances to the next heartbeat. It returns false at the end of the dump
+// or on error.
+func (d *DumpDecoder) Next() bool {
+	if d.err != nil {
+		return false
+	}
+	var err error
+	for {
+		switch d.state {
+		case dumpBeforeDays:
+			err = d.seekDays()
+		case dumpInDays:
+			err = d.nextDay()
+		case dumpInHeartbeats:
+			if d.dec.More() {
+				d.heartbeat = HeartbeatItem{}
+				if err = d.dec.Decode(&d.heartbeat); err == nil {
+					return true
+				}
+				break
+			}
+			err = d.closeDay()
+		default:
+			return false
+		}
+		if err != nil {
+			d.err = err
+			return false
+		}
+	}
+}
+
+// Day returns the date of the current heartbeat as in the dump. The date is
+// empty when the dump lists it after the day's heartbeats.
+func (d *DumpDecoder) Day() string {
+	return d.day
+}
+
+// Heartbeat returns the current heartbeat
+func (d *DumpDecoder) Heartbeat() HeartbeatItem {
+	return d.heartbeat
+}
+
+// Err returns the first error encountered while decoding
+func (d *DumpDecoder) Err() error {
+	return d.err
+}
+
+// seekDays skips the top level keys until the days array
+func (d *DumpDecoder) seekDays() error {
+	if err := d.expectDelim('{'); err != nil {
+		return err
+	}
+	for d.dec.More() {
+		key, err := d.stringToken()
+		if err != nil {
+			return err
+		}
+		if key == "days" {
+			if err = d.expectDelim('['); err != nil {
+				return err
+			}
+			d.state = dumpInDays
+			return nil
+		}
+		var skip json.RawMessage
+		if err = d.dec.Decode(&skip); err != nil {
+			return err
+		}
+	}
+	d.state = dumpDone
+	return nil
+}
+
+// nextDay reads the day keys until the heartbeats array
+func (d *DumpDecoder) nextDay() error {
+	if !d.dec.More() {
+		d.state = dumpDone
+		return nil
+	}
+	if err := d.expectDelim('{'); err != nil {
+		return err
+	}
+	d.day = ""
+	for d.dec.More() {
+		key, err := d.stringToken()
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "date":
+			if err = d.dec.Decode(&d.day); err != nil {
+				return err
+			}
+		case "heartbeats":
+			if err = d.expectDelim('['); err != nil {
+				return err
+			}
+			d.state = dumpInHeartbeats
+			return nil
+		default:
+			var skip json.RawMessage
+			if err = d.dec.Decode(&skip); err != nil {
+				return err
+			}
+		}
+	}
+	// day without heartbeats
+	return d.expectDelim('}')
+}
+
+// closeDay skips the remaining day keys after the heartbeats array
+func (d *DumpDecoder) closeDay() error {
+	if err := d.expectDelim(']'); err != nil {
+		return err
+	}
+	for d.dec.More() {
+		if _, err := d.stringToken(); err != nil {
+			return err
+		}
+		var skip json.RawMessage
+		if err := d.dec.Decode(&skip); err != nil {
+			return err
+		}
+	}
+	d.state = dumpInDays
+	return d.expectDelim('}')
+}
+
+func (d *DumpDecoder) expectDelim(delim json.Delim) error {
+	t, err := d.dec.Token()
+	if err != nil {
+		return err
+	}
+	if t != delim {
+		return fmt.Errorf("unexpected token %v in data dump, expected %v", t, delim)
+	}
+	return nil
+}
+
+func (d *DumpDecoder) stringToken() (string, error) {
+	t, err := d.dec.Token()
+	if err != nil {
+		return "", err
+	}
+	s, ok := t.(string)
+	if !ok {
+		return "", fmt.Errorf("unexpected token %v in data dump, expected key", t)
+	}
+	return s, nil
+}
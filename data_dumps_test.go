@@ -0,0 +1,154 @@
+package wakatime
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const (
+	dataDumpProcessing = `{"data": [{"created_at": "2015-04-28T07:08:06Z", "download_url": null, "expires": null, "has_failed": false, "id": "a1b2c3", "is_processing": true, "is_stuck": false, "percent_complete": 42.5, "status": "Processing heartbeats", "type": "heartbeats"}], "total": 1, "total_pages": 1}`
+	dataDumpReady      = `{"data": [{"created_at": "2015-04-28T07:08:06Z", "download_url": "https://wakatime.s3.amazonaws.com/dumps/a1b2c3.json", "expires": "2015-05-05T07:08:06Z", "has_failed": false, "id": "a1b2c3", "is_processing": false, "is_stuck": false, "percent_complete": 100, "status": "Completed", "type": "heartbeats"}], "total": 1, "total_pages": 1}`
+	dataDumpFailed     = `{"data": [{"created_at": "2015-04-28T07:08:06Z", "download_url": null, "has_failed": true, "id": "a1b2c3", "is_processing": false, "is_stuck": false, "percent_complete": 10, "status": "Failed", "type": "heartbeats"}], "total": 1, "total_pages": 1}`
+	heartbeatsDump     = `{
+  "range": {"end": 1430171999, "start": 1429567200},
+  "user": {"username": "aquilax", "days": [{"date": "not a day"}]},
+  "days": [
+    {
+      "date": "2015-04-23",
+      "grand_total": {"total_seconds": 11165},
+      "heartbeats": [
+        {"entity": "/home/aquilax/go-wakatime/wakatime.go", "type": "file", "time": 1429760000.5, "project": "go-wakatime", "language": "Go", "is_write": true},
+        {"entity": "/home/aquilax/go-wakatime/README.md", "type": "file", "time": 1429760060, "project": "go-wakatime", "language": "Markdown"}
+      ]
+    },
+    {
+      "date": "2015-04-24",
+      "heartbeats": []
+    },
+    {
+      "date": "2015-04-25",
+      "heartbeats": [
+        {"entity": "wakatime.com", "type": "domain", "time": 1429933000, "category": "browsing"}
+      ],
+      "grand_total": {"total_seconds": 60}
+    }
+  ]
+}`
+)
+
+// BodiesTransport responds with the given bodies in order, repeating the last
+type BodiesTransport struct {
+	bodies []string
+	calls  int
+}
+
+func (bt *BodiesTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	i := bt.calls
+	if i >= len(bt.bodies) {
+		i = len(bt.bodies) - 1
+	}
+	bt.calls++
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(bt.bodies[i])),
+	}, nil
+}
+
+func TestDataDumps(t *testing.T) {
+	Convey("Given wakatime", t, func() {
+		Convey("Data dump must be requested", func() {
+			dt := NewDummyTransport(`{"data": {"id": "a1b2c3", "is_processing": true, "type": "heartbeats"}}`)
+			wt := New(dt)
+			d, err := wt.CreateDataDump(context.Background(), CurrentUser, DataDumpHeartbeats, true)
+			So(err, ShouldBeNil)
+			So(d.Data.ID, ShouldEqual, "a1b2c3")
+			So(dt.req.Method, ShouldEqual, http.MethodPost)
+			So(dt.req.URL.Path, ShouldEqual, "/api/v1/users/current/data_dumps")
+			body, _ := ioutil.ReadAll(dt.req.Body)
+			So(string(body), ShouldEqual, `{"type":"heartbeats","email_when_finished":true}`)
+		})
+		Convey("Data dumps JSON must be correctly parsed", func() {
+			dt := NewDummyTransport(dataDumpReady)
+			wt := New(dt)
+			d, err := wt.DataDumps(context.Background(), CurrentUser)
+			So(err, ShouldBeNil)
+			So(dt.req.URL.Path, ShouldEqual, "/api/v1/users/current/data_dumps")
+			So(d.Data[0].DownloadURL, ShouldEqual, "https://wakatime.s3.amazonaws.com/dumps/a1b2c3.json")
+			So(d.Data[0].Expires.Format(time.RFC3339), ShouldEqual, "2015-05-05T07:08:06Z")
+			So(d.Data[0].IsReady(), ShouldBeTrue)
+		})
+		Convey("Wait must poll until the dump is ready", func() {
+			bt := &BodiesTransport{bodies: []string{dataDumpProcessing, dataDumpProcessing, dataDumpReady}}
+			wt := New(bt)
+			clock := NewFakeClock()
+			wt.clock = clock
+			d, err := wt.WaitDataDump(context.Background(), CurrentUser, "a1b2c3", time.Minute)
+			So(err, ShouldBeNil)
+			So(d.IsReady(), ShouldBeTrue)
+			So(bt.calls, ShouldEqual, 3)
+			So(clock.waits, ShouldResemble, []time.Duration{time.Minute, time.Minute})
+		})
+		Convey("Wait must not poll faster than the minimum interval", func() {
+			bt := &BodiesTransport{bodies: []string{dataDumpProcessing, dataDumpReady}}
+			wt := New(bt)
+			clock := NewFakeClock()
+			wt.clock = clock
+			_, err := wt.WaitDataDump(context.Background(), CurrentUser, "a1b2c3", 0)
+			So(err, ShouldBeNil)
+			So(clock.waits, ShouldResemble, []time.Duration{MinDataDumpPollInterval})
+		})
+		Convey("Wait must stop on failed dump", func() {
+			wt := New(&BodiesTransport{bodies: []string{dataDumpProcessing, dataDumpFailed}})
+			wt.clock = NewFakeClock()
+			d, err := wt.WaitDataDump(context.Background(), CurrentUser, "a1b2c3", time.Minute)
+			So(errors.Is(err, ErrDataDumpFailed), ShouldBeTrue)
+			So(d.HasFailed, ShouldBeTrue)
+		})
+		Convey("Wait must fail for unknown dump", func() {
+			wt := New(&BodiesTransport{bodies: []string{dataDumpReady}})
+			_, err := wt.WaitDataDump(context.Background(), CurrentUser, "unknown", time.Minute)
+			So(err, ShouldNotBeNil)
+		})
+	})
+	Convey("Given heartbeats dump", t, func() {
+		Convey("Heartbeats must be streamed with their days", func() {
+			d := NewDumpDecoder(strings.NewReader(heartbeatsDump))
+			var days []string
+			var hbs []HeartbeatItem
+			for d.Next() {
+				days = append(days, d.Day())
+				hbs = append(hbs, d.Heartbeat())
+			}
+			So(d.Err(), ShouldBeNil)
+			So(days, ShouldResemble, []string{"2015-04-23", "2015-04-23", "2015-04-25"})
+			So(len(hbs), ShouldEqual, 3)
+			So(hbs[0].Entity, ShouldEqual, "/home/aquilax/go-wakatime/wakatime.go")
+			So(hbs[0].Time, ShouldEqual, 1429760000.5)
+			So(hbs[0].IsWrite, ShouldBeTrue)
+			So(hbs[1].IsWrite, ShouldBeFalse)
+			So(hbs[2].Category, ShouldEqual, "browsing")
+			So(d.Next(), ShouldBeFalse)
+		})
+		Convey("Broken dump must report an error", func() {
+			d := NewDumpDecoder(strings.NewReader(`{"days": [{"date": "2015-04-23", "heartbeats": [{"entity": 1}]}]}`))
+			So(d.Next(), ShouldBeFalse)
+			So(d.Err(), ShouldNotBeNil)
+			d = NewDumpDecoder(strings.NewReader(`[]`))
+			So(d.Next(), ShouldBeFalse)
+			So(d.Err(), ShouldNotBeNil)
+		})
+		Convey("Dump without days must be empty", func() {
+			d := NewDumpDecoder(strings.NewReader(`{"user": {}}`))
+			So(d.Next(), ShouldBeFalse)
+			So(d.Err(), ShouldBeNil)
+		})
+	})
+}
@@ -0,0 +1,151 @@
+package wakatime
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Logger is the interface used by DebugTransport, satisfied by *log.Logger
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// nopLogger discards the messages
+type nopLogger struct{}
+
+func (nopLogger) Printf(format string, v ...interface{}) {}
+
+// DebugTransport implements http.RoundTripper and logs the requests and
+// responses. Put it under the authenticating transport to see the headers as
+// sent, credentials are always redacted.
+type DebugTransport struct {
+	Transport http.RoundTripper
+	// Logger receives the messages, they are discarded when nil which is
+	// useful with DumpDir only
+	Logger Logger
+	// LogHeaders logs the request and response headers
+	LogHeaders bool
+	// LogBody logs the response body
+	LogBody bool
+	// DumpDir is the directory where the raw responses are written, one file
+	// per response. Nothing is written when it is empty.
+	DumpDir string
+	// Clock is used for the latency and the dump file names, the system
+	// clock is used when nil
+	Clock Clock
+	seq   uint64
+}
+
+// NewDebugTransport creates new DebugTransport wrapping rt
+func NewDebugTransport(rt http.RoundTripper, logger Logger) *DebugTransport {
+	return &DebugTransport{
+		Transport: rt,
+		Logger:    logger,
+		Clock:     systemClock{},
+	}
+}
+
+// RoundTrip implements the http.RoundTripper method
+func (dt *DebugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	seq := atomic.AddUint64(&dt.seq, 1)
+	logger := dt.logger()
+	u := RedactURL(req.URL)
+	if dt.LogHeaders {
+		logger.Printf("#%d %s %s headers: %v", seq, req.Method, u, RedactHeader(req.Header))
+	}
+	clock := dt.clock()
+	start := clock.Now()
+	resp, err := dt.Transport.RoundTrip(req)
+	latency := clock.Now().Sub(start)
+	if err != nil {
+		logger.Printf("#%d %s %s error: %v (%s)", seq, req.Method, u, err, latency)
+		return resp, err
+	}
+	logger.Printf("#%d %s %s %d (%s)", seq, req.Method, u, resp.StatusCode, latency)
+	if dt.LogHeaders {
+		logger.Printf("#%d response headers: %v", seq, RedactHeader(resp.Header))
+	}
+	if !dt.LogBody && dt.DumpDir == "" {
+		return resp, nil
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if dt.LogBody {
+		logger.Printf("#%d response body: %s", seq, body)
+	}
+	if dt.DumpDir != "" {
+		if err := dt.dump(start, seq, req, resp); err != nil {
+			logger.Printf("#%d dump failed: %v", seq, err)
+		}
+		resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	return resp, nil
+}
+
+func (dt *DebugTransport) logger() Logger {
+	if dt.Logger == nil {
+		return nopLogger{}
+	}
+	return dt.Logger
+}
+
+func (dt *DebugTransport) clock() Clock {
+	if dt.Clock == nil {
+		return systemClock{}
+	}
+	return dt.Clock
+}
+
+// dump writes the raw response to a new file in DumpDir. Existing files are
+// never overwritten.
+func (dt *DebugTransport) dump(start time.Time, seq uint64, req *http.Request, resp *http.Response) error {
+	raw, err := httputil.DumpResponse(resp, true)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(dt.DumpDir, 0700); err != nil {
+		return err
+	}
+	name := dumpFileName(start, seq, req)
+	var f *os.File
+	for n := 2; ; n++ {
+		f, err = os.OpenFile(filepath.Join(dt.DumpDir, name+".http"), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if !os.IsExist(err) {
+			break
+		}
+		name = fmt.Sprintf("%s-%d", dumpFileName(start, seq, req), n)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(raw); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// dumpFileName returns name without extension like
+// 20150423T000000.000000000-000001-GET-users_current_stats
+func dumpFileName(start time.Time, seq uint64, req *http.Request) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '.':
+			return r
+		}
+		return '_'
+	}, strings.Trim(req.URL.Path, "/"))
+	return fmt.Sprintf("%s-%06d-%s-%s", start.UTC().Format("20060102T150405.000000000"), seq, req.Method, name)
+}
@@ -0,0 +1,114 @@
+package wakatime
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// ErrorTransport fails every request
+type ErrorTransport struct{}
+
+func (et *ErrorTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestDebugTransport(t *testing.T) {
+	Convey("Given debug transport under basic transport", t, func() {
+		var buf bytes.Buffer
+		st := &StatusTransport{http.StatusOK, http.Header{"Content-Type": {"application/json"}}, users}
+		dt := NewDebugTransport(st, log.New(&buf, "", 0))
+		dt.Clock = NewFakeClock()
+		bt := NewBasicTransport("key")
+		bt.Transport = dt
+		wt := New(bt)
+		Convey("Request summary must be logged", func() {
+			_, err := wt.Users(CurrentUser)
+			So(err, ShouldBeNil)
+			So(buf.String(), ShouldEqual, "#1 GET https://wakatime.com/api/v1/users/current 200 (0s)\n")
+		})
+		Convey("Headers must be logged with redacted authorization", func() {
+			dt.LogHeaders = true
+			_, err := wt.Users(CurrentUser)
+			So(err, ShouldBeNil)
+			So(buf.String(), ShouldContainSubstring, "Authorization:[Basic REDACTED]")
+			So(buf.String(), ShouldNotContainSubstring, "a2V5")
+			So(buf.String(), ShouldContainSubstring, "#1 response headers: map[Content-Type:[application/json]]")
+		})
+		Convey("Body must be logged and still be readable", func() {
+			dt.LogBody = true
+			u, err := wt.Users(CurrentUser)
+			So(err, ShouldBeNil)
+			So(u.Data.Username, ShouldEqual, "aquilax")
+			So(buf.String(), ShouldContainSubstring, "#1 response body: "+users)
+		})
+		Convey("Errors must be logged", func() {
+			dt.Transport = &ErrorTransport{}
+			_, err := wt.Users(CurrentUser)
+			So(err, ShouldNotBeNil)
+			So(buf.String(), ShouldEqual, "#1 GET https://wakatime.com/api/v1/users/current error: connection refused (0s)\n")
+		})
+		Convey("Responses must be dumped to the directory", func() {
+			dir, err := ioutil.TempDir("", "wakatime")
+			So(err, ShouldBeNil)
+			defer os.RemoveAll(dir)
+			dt.DumpDir = filepath.Join(dir, "dumps")
+			_, err = wt.Users(CurrentUser)
+			So(err, ShouldBeNil)
+			_, err = wt.Users("alice")
+			So(err, ShouldBeNil)
+			raw, err := ioutil.ReadFile(filepath.Join(dt.DumpDir, "20150423T000000.000000000-000001-GET-api_v1_users_current.http"))
+			So(err, ShouldBeNil)
+			So(strings.HasPrefix(string(raw), "HTTP/0.0 200 OK\r\n"), ShouldBeTrue)
+			So(string(raw), ShouldEndWith, users)
+			_, err = os.Stat(filepath.Join(dt.DumpDir, "20150423T000000.000000000-000002-GET-api_v1_users_alice.http"))
+			So(err, ShouldBeNil)
+			Convey("Another transport must not overwrite the dumps", func() {
+				other := NewDebugTransport(st, log.New(&buf, "", 0))
+				other.Clock = dt.Clock
+				other.DumpDir = dt.DumpDir
+				_, err := New(other).Users(CurrentUser)
+				So(err, ShouldBeNil)
+				raw, err := ioutil.ReadFile(filepath.Join(dt.DumpDir, "20150423T000000.000000000-000001-GET-api_v1_users_current-2.http"))
+				So(err, ShouldBeNil)
+				So(string(raw), ShouldEndWith, users)
+				files, err := ioutil.ReadDir(dt.DumpDir)
+				So(err, ShouldBeNil)
+				So(len(files), ShouldEqual, 3)
+			})
+		})
+	})
+	Convey("Given debug transport without clock", t, func() {
+		var buf bytes.Buffer
+		dt := &DebugTransport{Transport: NewDummyTransport(users), Logger: log.New(&buf, "", 0)}
+		Convey("The system clock must be used", func() {
+			_, err := New(dt).Users(CurrentUser)
+			So(err, ShouldBeNil)
+			So(buf.String(), ShouldStartWith, "#1 GET https://wakatime.com/api/v1/users/current 200 (")
+		})
+	})
+	Convey("Given debug transport without logger", t, func() {
+		dir, err := ioutil.TempDir("", "wakatime")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		dt := NewDebugTransport(NewDummyTransport(users), nil)
+		dt.DumpDir = dir
+		dt.LogHeaders = true
+		dt.LogBody = true
+		Convey("Responses must still be dumped", func() {
+			_, err := New(dt).Users(CurrentUser)
+			So(err, ShouldBeNil)
+			files, err := ioutil.ReadDir(dir)
+			So(err, ShouldBeNil)
+			So(len(files), ShouldEqual, 1)
+		})
+	})
+}
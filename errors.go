@@ -0,0 +1,139 @@
+package wakatime
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// APIError is returned when the API responds with an unexpected status code
+type APIError struct {
+	// StatusCode is the HTTP status code of the response
+	StatusCode int
+	// Message is the main error message returned by the API
+	Message string
+	// Errors contains all error messages returned by the API
+	Errors []string
+	// URL is the requested URL
+	URL string
+	// RetryAfter is the wait time requested by the API, zero when not set
+	RetryAfter time.Duration
+	// Header contains the response headers
+	Header http.Header
+	// Body is the raw response body
+	Body []byte
+}
+
+// apiErrorBody is the error payload as returned by the API
+type apiErrorBody struct {
+	Error  string
+	Errors json.RawMessage
+}
+
+func newAPIError(url string, resp *http.Response, body []byte) *APIError {
+	e := &APIError{
+		StatusCode: resp.StatusCode,
+		URL:        url,
+		Header:     resp.Header,
+		Body:       body,
+		RetryAfter: parseRetryAfter(resp.Header, time.Now()),
+	}
+	var eb apiErrorBody
+	if err := json.Unmarshal(body, &eb); err != nil {
+		return e
+	}
+	if eb.Error != "" {
+		e.Errors = append(e.Errors, eb.Error)
+	}
+	e.Errors = append(e.Errors, parseErrorMessages(eb.Errors)...)
+	if len(e.Errors) > 0 {
+		e.Message = e.Errors[0]
+	}
+	return e
+}
+
+// parseErrorMessages flattens the errors field which can be a string, a list
+// of strings or a map of field names to lists of strings
+func parseErrorMessages(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return []string{s}
+	}
+	var l []string
+	if err := json.Unmarshal(raw, &l); err == nil {
+		return l
+	}
+	var m map[string][]string
+	if err := json.Unmarshal(raw, &m); err == nil {
+		fields := make([]string, 0, len(m))
+		for field := range m {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		var result []string
+		for _, field := range fields {
+			for _, msg := range m[field] {
+				result = append(result, field+": "+msg)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// parseRetryAfter parses the Retry-After header, given in seconds or as HTTP date
+func parseRetryAfter(h http.Header, now time.Time) time.Duration {
+	v := strings.TrimSpace(h.Get("Retry-After"))
+	if v == "" {
+		return 0
+	}
+	if sec, err := strconv.Atoi(v); err == nil {
+		if sec < 0 {
+			return 0
+		}
+		return time.Duration(sec) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("HTTP Error: %d", e.StatusCode)
+	}
+	return fmt.Sprintf("HTTP Error: %d: %s", e.StatusCode, e.Message)
+}
+
+func hasStatus(err error, code int) bool {
+	var e *APIError
+	return errors.As(err, &e) && e.StatusCode == code
+}
+
+// IsUnauthorized reports whether err is an API error caused by missing or
+// invalid credentials
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+// IsRateLimited reports whether err is an API error caused by rate limiting
+func IsRateLimited(err error) bool {
+	return hasStatus(err, http.StatusTooManyRequests)
+}
+
+// IsNotFound reports whether err is an API error for missing resource
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
@@ -0,0 +1,87 @@
+package wakatime
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// StatusTransport responds with the given status code, headers and body
+type StatusTransport struct {
+	statusCode int
+	header     http.Header
+	content    string
+}
+
+func (st *StatusTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		Status:     fmt.Sprintf("%d %s", st.statusCode, http.StatusText(st.statusCode)),
+		StatusCode: st.statusCode,
+		Header:     st.header,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(st.content)),
+		Request:    req,
+	}, nil
+}
+
+func TestAPIError(t *testing.T) {
+	Convey("Given wakatime responding with 401", t, func() {
+		wt := New(&StatusTransport{http.StatusUnauthorized, http.Header{}, `{"error": "Unauthorized"}`})
+		Convey("The error must be unauthorized API error", func() {
+			_, err := wt.Users(CurrentUser)
+			So(err, ShouldNotBeNil)
+			So(IsUnauthorized(err), ShouldBeTrue)
+			So(IsNotFound(err), ShouldBeFalse)
+			So(IsRateLimited(err), ShouldBeFalse)
+			So(err.Error(), ShouldEqual, "HTTP Error: 401: Unauthorized")
+			e, ok := err.(*APIError)
+			So(ok, ShouldBeTrue)
+			So(e.StatusCode, ShouldEqual, http.StatusUnauthorized)
+			So(e.URL, ShouldEqual, "https://wakatime.com/api/v1/users/current")
+			So(string(e.Body), ShouldEqual, `{"error": "Unauthorized"}`)
+		})
+	})
+	Convey("Given wakatime responding with 429", t, func() {
+		wt := New(&StatusTransport{http.StatusTooManyRequests, http.Header{"Retry-After": {"30"}}, `{"errors": ["Too many requests", "Slow down"]}`})
+		Convey("The error must carry the rate limit details", func() {
+			_, err := wt.Users(CurrentUser)
+			So(IsRateLimited(err), ShouldBeTrue)
+			e := err.(*APIError)
+			So(e.RetryAfter, ShouldEqual, 30*time.Second)
+			So(e.Message, ShouldEqual, "Too many requests")
+			So(e.Errors, ShouldResemble, []string{"Too many requests", "Slow down"})
+			So(e.Header.Get("Retry-After"), ShouldEqual, "30")
+		})
+	})
+	Convey("Given wakatime responding with 404 without body", t, func() {
+		wt := New(&StatusTransport{http.StatusNotFound, http.Header{}, ``})
+		Convey("The error must be not found API error", func() {
+			_, err := wt.Users("unknown")
+			So(IsNotFound(err), ShouldBeTrue)
+			So(err.Error(), ShouldEqual, "HTTP Error: 404")
+		})
+	})
+	Convey("Given wakatime responding with 202", t, func() {
+		wt := New(&StatusTransport{http.StatusAccepted, http.Header{}, `{"data": {"status": "pending_update"}}`})
+		Convey("The error must carry the status code", func() {
+			_, err := wt.Stats(CurrentUser, Last7Days, nil, nil, nil)
+			e, ok := err.(*APIError)
+			So(ok, ShouldBeTrue)
+			So(e.StatusCode, ShouldEqual, http.StatusAccepted)
+		})
+	})
+	Convey("Given validation errors", t, func() {
+		msgs := parseErrorMessages([]byte(`{"time": ["is required"], "entity": ["is too long"]}`))
+		So(msgs, ShouldResemble, []string{"entity: is too long", "time: is required"})
+	})
+	Convey("Given Retry-After header as HTTP date", t, func() {
+		now := time.Date(2015, 4, 23, 4, 32, 26, 0, time.UTC)
+		h := http.Header{"Retry-After": {now.Add(90 * time.Second).Format(http.TimeFormat)}}
+		So(parseRetryAfter(h, now), ShouldEqual, 90*time.Second)
+		So(parseRetryAfter(http.Header{}, now), ShouldEqual, 0)
+	})
+}
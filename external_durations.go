@@ -0,0 +1,209 @@
+package wakatime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// ErrInvalidExternalDuration is returned when an external duration fails the
+// validation before sending
+var ErrInvalidExternalDuration = errors.New("invalid external duration")
+
+// External duration entity types
+const (
+	EntityTypeFile   = "file"
+	EntityTypeDomain = "domain"
+	EntityTypeApp    = "app"
+)
+
+// categories accepted for heartbeats and external durations
+var categories = map[string]bool{
+	"coding":         true,
+	"ai coding":      true,
+	"building":       true,
+	"indexing":       true,
+	"debugging":      true,
+	"browsing":       true,
+	"running tests":  true,
+	"writing tests":  true,
+	"manual testing": true,
+	"writing docs":   true,
+	"code reviewing": true,
+	"communicating":  true,
+	"researching":    true,
+	"learning":       true,
+	"designing":      true,
+	"meeting":        true,
+	"planning":       true,
+	"supporting":     true,
+	"advising":       true,
+	"translating":    true,
+}
+
+// ExternalDuration contains activity logged from outside of the editors
+type ExternalDuration struct {
+	ID         string  `json:"id,omitempty"`
+	ExternalID string  `json:"external_id"`
+	Entity     string  `json:"entity"`
+	Type       string  `json:"type"`
+	Category   string  `json:"category,omitempty"`
+	StartTime  float64 `json:"start_time"`
+	EndTime    float64 `json:"end_time"`
+	Project    string  `json:"project,omitempty"`
+	Branch     string  `json:"branch,omitempty"`
+	Language   string  `json:"language,omitempty"`
+	Meta       string  `json:"meta,omitempty"`
+}
+
+// ExternalDurations contains the external durations for single day
+type ExternalDurations struct {
+	Branches []string
+	Data     []ExternalDuration
+	End      Time
+	Start    Time
+	Timezone string
+}
+
+// ExternalDurationResult is the result of a single external duration in a bulk
+// request
+type ExternalDurationResult struct {
+	// StatusCode is the HTTP status code for the external duration
+	StatusCode int
+	// Data contains the created external duration on success
+	Data *ExternalDuration
+	// Error contains the error message returned for the external duration
+	Error string
+}
+
+// externalDurationResponse is the body of the response for a single external
+// duration
+type externalDurationResponse struct {
+	Data   *ExternalDuration
+	Error  string
+	Errors json.RawMessage
+}
+
+// deleteExternalDurationsRequest is the body of the bulk delete request
+type deleteExternalDurationsRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// Validate checks the external duration for missing or invalid fields
+func (ed *ExternalDuration) Validate() error {
+	switch {
+	case ed.ExternalID == "":
+		return fmt.Errorf("%w: external_id is required", ErrInvalidExternalDuration)
+	case ed.Entity == "":
+		return fmt.Errorf("%w: entity is required", ErrInvalidExternalDuration)
+	case ed.Type != EntityTypeFile && ed.Type != EntityTypeDomain && ed.Type != EntityTypeApp:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidExternalDuration, ed.Type)
+	case ed.Category != "" && !IsValidCategory(ed.Category):
+		return fmt.Errorf("%w: unknown category %q", ErrInvalidExternalDuration, ed.Category)
+	case ed.StartTime <= 0:
+		return fmt.Errorf("%w: start_time is required", ErrInvalidExternalDuration)
+	case ed.EndTime <= ed.StartTime:
+		return fmt.Errorf("%w: end_time must be after start_time", ErrInvalidExternalDuration)
+	}
+	return nil
+}
+
+// IsValidCategory reports whether the category is accepted for heartbeats and
+// external durations
+func IsValidCategory(category string) bool {
+	return categories[category]
+}
+
+// CreateExternalDuration validates and sends single external duration for the
+// user
+func (wt *WakaTime) CreateExternalDuration(ctx context.Context, user string, ed ExternalDuration) (*ExternalDuration, error) {
+	var err error
+	if err = ed.Validate(); err != nil {
+		return nil, err
+	}
+	var u *url.URL
+	if u, err = wt.apiURL("users", user, "external_durations"); err != nil {
+		return nil, err
+	}
+	var content []byte
+	if content, err = wt.sendURL(ctx, http.MethodPost, u.String(), ed); err != nil {
+		return nil, err
+	}
+	var er externalDurationResponse
+	if err = json.Unmarshal(content, &er); err != nil {
+		return nil, err
+	}
+	return er.Data, nil
+}
+
+// CreateExternalDurationsBulk validates and sends the external durations for the
+// user in single request. Nothing is sent when any of them is invalid.
+func (wt *WakaTime) CreateExternalDurationsBulk(ctx context.Context, user string, eds []ExternalDuration) ([]ExternalDurationResult, error) {
+	var err error
+	for i := range eds {
+		if err = eds[i].Validate(); err != nil {
+			return nil, fmt.Errorf("external duration %d: %w", i, err)
+		}
+	}
+	var u *url.URL
+	if u, err = wt.apiURL("users", user, "external_durations.bulk"); err != nil {
+		return nil, err
+	}
+	var content []byte
+	if content, err = wt.sendURL(ctx, http.MethodPost, u.String(), eds); err != nil {
+		return nil, err
+	}
+	var items []bulkItem
+	if items, err = parseBulkResponse(content); err != nil {
+		return nil, err
+	}
+	results := make([]ExternalDurationResult, len(items))
+	for i, item := range items {
+		var er externalDurationResponse
+		if err = json.Unmarshal(item.Body, &er); err != nil {
+			return nil, err
+		}
+		results[i] = ExternalDurationResult{
+			StatusCode: item.StatusCode,
+			Data:       er.Data,
+			Error:      errorMessage(er.Error, er.Errors),
+		}
+	}
+	return results, nil
+}
+
+// ListExternalDurations fetches the user's external durations filtered by opts
+func (wt *WakaTime) ListExternalDurations(ctx context.Context, user string, opts *DurationsOptions) (*ExternalDurations, error) {
+	var err error
+	var u *url.URL
+	if u, err = wt.apiURL("users", user, "external_durations"); err != nil {
+		return nil, err
+	}
+	q := opts.Values()
+	wt.setTimezone(q)
+	u.RawQuery = q.Encode()
+	var content []byte
+	if content, err = wt.fetchURL(ctx, u.String()); err != nil {
+		return nil, err
+	}
+	var ed ExternalDurations
+	if err = json.Unmarshal(content, &ed); err != nil {
+		return nil, err
+	}
+	return &ed, nil
+}
+
+// DeleteExternalDurations deletes the user's external durations with the given
+// ids
+func (wt *WakaTime) DeleteExternalDurations(ctx context.Context, user string, ids []string) error {
+	var err error
+	var u *url.URL
+	if u, err = wt.apiURL("users", user, "external_durations.bulk"); err != nil {
+		return err
+	}
+	_, err = wt.sendURL(ctx, http.MethodDelete, u.String(), deleteExternalDurationsRequest{IDs: ids})
+	return err
+}
@@ -0,0 +1,139 @@
+package wakatime
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const externalDurations = `{
+  "branches": ["master"],
+  "data": [
+    {
+      "branch": "master",
+      "category": "meeting",
+      "end_time": 1430024400.5,
+      "entity": "Sprint planning",
+      "external_id": "calendar-42",
+      "id": "4b6d8f0a-2c4e-4a6c-8e0a-2c4e6a8c0e2a",
+      "language": null,
+      "project": "go-wakatime",
+      "start_time": 1430020800,
+      "type": "app"
+    }
+  ],
+  "end": 1430085599,
+  "start": 1429999200,
+  "timezone": "Europe/Stockholm"
+}`
+
+func TestExternalDurations(t *testing.T) {
+	valid := ExternalDuration{
+		ExternalID: "calendar-42",
+		Entity:     "Sprint planning",
+		Type:       EntityTypeApp,
+		Category:   "meeting",
+		StartTime:  1430020800,
+		EndTime:    1430024400.5,
+		Project:    "go-wakatime",
+	}
+	Convey("Given external duration", t, func() {
+		Convey("Valid duration must pass", func() {
+			So(valid.Validate(), ShouldBeNil)
+		})
+		Convey("Current categories must be accepted", func() {
+			for _, c := range []string{"ai coding", "supporting", "advising", "translating"} {
+				ed := valid
+				ed.Category = c
+				So(ed.Validate(), ShouldBeNil)
+			}
+			So(IsValidCategory("sleeping"), ShouldBeFalse)
+		})
+		Convey("Invalid durations must fail", func() {
+			invalid := []func(ed *ExternalDuration){
+				func(ed *ExternalDuration) { ed.ExternalID = "" },
+				func(ed *ExternalDuration) { ed.Entity = "" },
+				func(ed *ExternalDuration) { ed.Type = "meeting" },
+				func(ed *ExternalDuration) { ed.Category = "sleeping" },
+				func(ed *ExternalDuration) { ed.StartTime = 0 },
+				func(ed *ExternalDuration) { ed.EndTime = ed.StartTime },
+			}
+			for _, modify := range invalid {
+				ed := valid
+				modify(&ed)
+				So(errors.Is(ed.Validate(), ErrInvalidExternalDuration), ShouldBeTrue)
+			}
+		})
+	})
+	Convey("Given wakatime", t, func() {
+		Convey("Single duration must be sent", func() {
+			dt := NewDummyTransport(`{"data": {"id": "4b6d8f0a", "external_id": "calendar-42", "entity": "Sprint planning", "type": "app", "start_time": 1430020800, "end_time": 1430024400.5}}`)
+			wt := New(dt)
+			ed, err := wt.CreateExternalDuration(context.Background(), CurrentUser, valid)
+			So(err, ShouldBeNil)
+			So(ed.ID, ShouldEqual, "4b6d8f0a")
+			So(dt.req.Method, ShouldEqual, http.MethodPost)
+			So(dt.req.URL.String(), ShouldEqual, "https://wakatime.com/api/v1/users/current/external_durations")
+			body, _ := ioutil.ReadAll(dt.req.Body)
+			So(string(body), ShouldEqual, `{"external_id":"calendar-42","entity":"Sprint planning","type":"app","category":"meeting","start_time":1430020800,"end_time":1430024400.5,"project":"go-wakatime"}`)
+		})
+		Convey("Invalid duration must not be sent", func() {
+			dt := NewDummyTransport("")
+			wt := New(dt)
+			ed := valid
+			ed.Entity = ""
+			_, err := wt.CreateExternalDuration(context.Background(), CurrentUser, ed)
+			So(errors.Is(err, ErrInvalidExternalDuration), ShouldBeTrue)
+			So(dt.req, ShouldBeNil)
+		})
+		Convey("Bulk durations must be sent", func() {
+			dt := NewDummyTransport(`{"responses": [[{"data": {"id": "4b6d8f0a", "external_id": "calendar-42"}}, 201], [{"error": "Duplicate external_id"}, 400]]}`)
+			wt := New(dt)
+			res, err := wt.CreateExternalDurationsBulk(context.Background(), CurrentUser, []ExternalDuration{valid, valid})
+			So(err, ShouldBeNil)
+			So(dt.req.URL.String(), ShouldEqual, "https://wakatime.com/api/v1/users/current/external_durations.bulk")
+			So(len(res), ShouldEqual, 2)
+			So(res[0].StatusCode, ShouldEqual, 201)
+			So(res[0].Data.ID, ShouldEqual, "4b6d8f0a")
+			So(res[1].StatusCode, ShouldEqual, 400)
+			So(res[1].Error, ShouldEqual, "Duplicate external_id")
+		})
+		Convey("Bulk with invalid duration must not be sent", func() {
+			dt := NewDummyTransport("")
+			wt := New(dt)
+			ed := valid
+			ed.Type = ""
+			_, err := wt.CreateExternalDurationsBulk(context.Background(), CurrentUser, []ExternalDuration{valid, ed})
+			So(errors.Is(err, ErrInvalidExternalDuration), ShouldBeTrue)
+			So(err.Error(), ShouldStartWith, "external duration 1:")
+			So(dt.req, ShouldBeNil)
+		})
+		Convey("Durations must be listed", func() {
+			dt := NewDummyTransport(externalDurations)
+			wt := New(dt)
+			ed, err := wt.ListExternalDurations(context.Background(), CurrentUser, &DurationsOptions{Date: time.Date(2015, 4, 26, 0, 0, 0, 0, time.UTC)})
+			So(err, ShouldBeNil)
+			So(dt.req.URL.Path, ShouldEqual, "/api/v1/users/current/external_durations")
+			So(dt.req.URL.RawQuery, ShouldEqual, "date=04%2F26%2F2015")
+			So(len(ed.Data), ShouldEqual, 1)
+			So(ed.Data[0].ExternalID, ShouldEqual, "calendar-42")
+			So(ed.Data[0].EndTime, ShouldEqual, 1430024400.5)
+			So(ed.Start.Time().Unix(), ShouldEqual, 1429999200)
+		})
+		Convey("Durations must be deleted", func() {
+			dt := NewDummyTransport("")
+			wt := New(dt)
+			err := wt.DeleteExternalDurations(context.Background(), CurrentUser, []string{"4b6d8f0a"})
+			So(err, ShouldBeNil)
+			So(dt.req.Method, ShouldEqual, http.MethodDelete)
+			So(dt.req.URL.String(), ShouldEqual, "https://wakatime.com/api/v1/users/current/external_durations.bulk")
+			body, _ := ioutil.ReadAll(dt.req.Body)
+			So(string(body), ShouldEqual, `{"ids":["4b6d8f0a"]}`)
+		})
+	})
+}
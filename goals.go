@@ -0,0 +1,202 @@
+package wakatime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"time"
+)
+
+// GoalStatus is the evaluated progress of a goal
+type GoalStatus string
+
+// Goal progress statuses
+const (
+	GoalOnTrack GoalStatus = "on_track"
+	GoalBehind  GoalStatus = "behind"
+	GoalReached GoalStatus = "reached"
+)
+
+// ErrNoGoalData is returned when the goal has no chart data to evaluate
+var ErrNoGoalData = errors.New("goal has no chart data")
+
+// GoalRange contains the period of a goal chart item
+type GoalRange struct {
+	Date     string
+	End      Time
+	Start    Time
+	Text     string
+	Timezone string
+}
+
+// GoalChartData contains the goal progress for single period
+type GoalChartData struct {
+	ActualSeconds     float64 `json:"actual_seconds"`
+	ActualSecondsText string  `json:"actual_seconds_text"`
+	GoalSeconds       float64 `json:"goal_seconds"`
+	GoalSecondsText   string  `json:"goal_seconds_text"`
+	Range             GoalRange
+	RangeStatus       string `json:"range_status"`
+	RangeStatusReason string `json:"range_status_reason"`
+}
+
+// GoalSubscriber contains the user receiving goal notifications
+type GoalSubscriber struct {
+	Email          string
+	EmailFrequency string `json:"email_frequency"`
+	FullName       string `json:"full_name"`
+	UserID         string `json:"user_id"`
+	Username       string
+}
+
+// GoalOwner contains the user owning the goal
+type GoalOwner struct {
+	DisplayName string `json:"display_name"`
+	Email       string
+	FullName    string `json:"full_name"`
+	ID          string
+	Photo       string
+	Username    string
+}
+
+// GoalData contains single goal
+type GoalData struct {
+	AverageStatus           string          `json:"average_status"`
+	ChartData               []GoalChartData `json:"chart_data"`
+	CreatedAt               time.Time       `json:"created_at"`
+	CumulativeStatus        string          `json:"cumulative_status"`
+	Delta                   string
+	Editors                 []string
+	ID                      string
+	IgnoreDays              []string `json:"ignore_days"`
+	IgnoreZeroDays          bool     `json:"ignore_zero_days"`
+	ImproveByPercent        *float64 `json:"improve_by_percent"`
+	IsCurrentUserOwner      bool     `json:"is_current_user_owner"`
+	IsEnabled               bool     `json:"is_enabled"`
+	IsInverse               bool     `json:"is_inverse"`
+	IsSnoozed               bool     `json:"is_snoozed"`
+	IsTweeting              bool     `json:"is_tweeting"`
+	Languages               []string
+	ModifiedAt              *time.Time `json:"modified_at"`
+	Owner                   GoalOwner
+	Projects                []string
+	RangeText               string `json:"range_text"`
+	Seconds                 int
+	SnoozeUntil             *time.Time `json:"snooze_until"`
+	Status                  string
+	StatusPercentCalculated int `json:"status_percent_calculated"`
+	Subscribers             []GoalSubscriber
+	Title                   string
+	Type                    string
+}
+
+// Goals contains the goals report
+type Goals struct {
+	Data       []GoalData
+	Total      int
+	TotalPages int `json:"total_pages"`
+}
+
+// Goal contains single goal report
+type Goal struct {
+	Data GoalData
+}
+
+// GoalProgress contains the evaluated goal progress for a period
+type GoalProgress struct {
+	Status GoalStatus
+	// ActualSeconds is the time spent in the period
+	ActualSeconds float64
+	// GoalSeconds is the goal for the whole period
+	GoalSeconds float64
+	// ExpectedSeconds is the part of the goal expected by now
+	ExpectedSeconds float64
+	// Percent is the part of the goal reached
+	Percent float64
+	// Period is the evaluated chart item
+	Period GoalChartData
+}
+
+// Goals fetches the user's goals
+func (wt *WakaTime) Goals(ctx context.Context, user string) (*Goals, error) {
+	var err error
+	var u *url.URL
+	if u, err = wt.apiURL("users", user, "goals"); err != nil {
+		return nil, err
+	}
+	var content []byte
+	if content, err = wt.fetchURL(ctx, u.String()); err != nil {
+		return nil, err
+	}
+	var g Goals
+	if err = json.Unmarshal(content, &g); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// Goal fetches single user's goal by id
+func (wt *WakaTime) Goal(ctx context.Context, user, id string) (*Goal, error) {
+	var err error
+	var u *url.URL
+	if u, err = wt.apiURL("users", user, "goals", id); err != nil {
+		return nil, err
+	}
+	var content []byte
+	if content, err = wt.fetchURL(ctx, u.String()); err != nil {
+		return nil, err
+	}
+	var g Goal
+	if err = json.Unmarshal(content, &g); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// Progress evaluates the goal progress for the period containing now. The last
+// period is used when none of them contains now.
+func (g *GoalData) Progress(now time.Time) (*GoalProgress, error) {
+	if len(g.ChartData) == 0 {
+		return nil, ErrNoGoalData
+	}
+	period := g.ChartData[len(g.ChartData)-1]
+	for _, cd := range g.ChartData {
+		if !now.Before(cd.Range.Start.Time()) && !now.After(cd.Range.End.Time()) {
+			period = cd
+			break
+		}
+	}
+	p := &GoalProgress{
+		ActualSeconds: period.ActualSeconds,
+		GoalSeconds:   period.GoalSeconds,
+		Period:        period,
+	}
+	start, end := period.Range.Start.Time(), period.Range.End.Time()
+	elapsed := 1.0
+	if now.Before(end) && end.After(start) {
+		elapsed = float64(now.Sub(start)) / float64(end.Sub(start))
+		if elapsed < 0 {
+			elapsed = 0
+		}
+	}
+	p.ExpectedSeconds = p.GoalSeconds * elapsed
+	if p.GoalSeconds > 0 {
+		p.Percent = p.ActualSeconds / p.GoalSeconds * 100
+	}
+	switch {
+	case g.IsInverse && p.ActualSeconds > p.GoalSeconds:
+		p.Status = GoalBehind
+	case g.IsInverse && elapsed >= 1:
+		p.Status = GoalReached
+	case g.IsInverse:
+		p.Status = GoalOnTrack
+	case p.ActualSeconds >= p.GoalSeconds:
+		p.Status = GoalReached
+	case p.ActualSeconds >= p.ExpectedSeconds:
+		p.Status = GoalOnTrack
+	default:
+		p.Status = GoalBehind
+	}
+	return p, nil
+}
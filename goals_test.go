@@ -0,0 +1,170 @@
+package wakatime
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const goals = `{
+  "data": [
+    {
+      "average_status": "success",
+      "chart_data": [
+        {
+          "actual_seconds": 40215.5,
+          "actual_seconds_text": "11 hrs 10 mins",
+          "goal_seconds": 36000,
+          "goal_seconds_text": "10 hrs",
+          "range": {
+            "date": "2015-04-13",
+            "end": "2015-04-19T21:59:59Z",
+            "start": "2015-04-12T22:00:00Z",
+            "text": "Apr 13th until Apr 19th",
+            "timezone": "Europe/Stockholm"
+          },
+          "range_status": "success",
+          "range_status_reason": "coded 11 hrs 10 mins which is 1 hr 10 mins more than your weekly goal of 10 hrs"
+        },
+        {
+          "actual_seconds": 14400,
+          "actual_seconds_text": "4 hrs",
+          "goal_seconds": 36000,
+          "goal_seconds_text": "10 hrs",
+          "range": {
+            "date": "2015-04-20",
+            "end": "2015-04-26T21:59:59Z",
+            "start": "2015-04-19T22:00:00Z",
+            "text": "Apr 20th until Apr 26th",
+            "timezone": "Europe/Stockholm"
+          },
+          "range_status": "pending",
+          "range_status_reason": "coded 4 hrs so far this week"
+        }
+      ],
+      "created_at": "2015-04-01T10:00:00Z",
+      "cumulative_status": "success",
+      "delta": "week",
+      "editors": [],
+      "id": "f2b7b1b0-3a52-4c3e-8b4b-3a0b1c9c8f12",
+      "ignore_days": ["saturday", "sunday"],
+      "ignore_zero_days": true,
+      "improve_by_percent": null,
+      "is_current_user_owner": true,
+      "is_enabled": true,
+      "is_inverse": false,
+      "is_snoozed": false,
+      "is_tweeting": false,
+      "languages": ["Go"],
+      "modified_at": null,
+      "owner": {
+        "display_name": "aquilax",
+        "email": "aquilax@example.com",
+        "full_name": "Full Name",
+        "id": "e9b45851-991b-4755-ffff-6355d927f472",
+        "photo": "https://wakatime.com/photo/e9b45851",
+        "username": "aquilax"
+      },
+      "projects": [],
+      "range_text": "per week",
+      "seconds": 36000,
+      "snooze_until": null,
+      "status": "success",
+      "status_percent_calculated": 100,
+      "subscribers": [
+        {
+          "email": "aquilax@example.com",
+          "email_frequency": "weekly",
+          "full_name": "Full Name",
+          "user_id": "e9b45851-991b-4755-ffff-6355d927f472",
+          "username": "aquilax"
+        }
+      ],
+      "title": "Code 10 hrs per week in Go",
+      "type": "coding"
+    }
+  ],
+  "total": 1,
+  "total_pages": 1
+}`
+
+func TestGoals(t *testing.T) {
+	Convey("Given wakatime", t, func() {
+		dt := NewDummyTransport(goals)
+		wt := New(dt)
+		Convey("Goals JSON must be correctly parsed", func() {
+			g, err := wt.Goals(context.Background(), CurrentUser)
+			So(err, ShouldBeNil)
+			So(dt.req.URL.Path, ShouldEqual, "/api/v1/users/current/goals")
+			So(g.Total, ShouldEqual, 1)
+			So(g.TotalPages, ShouldEqual, 1)
+			So(len(g.Data), ShouldEqual, 1)
+			goal := g.Data[0]
+			So(goal.ID, ShouldEqual, "f2b7b1b0-3a52-4c3e-8b4b-3a0b1c9c8f12")
+			So(goal.Title, ShouldEqual, "Code 10 hrs per week in Go")
+			So(goal.Delta, ShouldEqual, "week")
+			So(goal.Seconds, ShouldEqual, 36000)
+			So(goal.IgnoreDays, ShouldResemble, []string{"saturday", "sunday"})
+			So(goal.Languages, ShouldResemble, []string{"Go"})
+			So(goal.ImproveByPercent, ShouldBeNil)
+			So(goal.ModifiedAt, ShouldBeNil)
+			So(goal.Owner.Username, ShouldEqual, "aquilax")
+			So(len(goal.ChartData), ShouldEqual, 2)
+			So(goal.ChartData[0].ActualSeconds, ShouldEqual, 40215.5)
+			So(goal.ChartData[0].Range.Start.Time().UTC().Format(time.RFC3339), ShouldEqual, "2015-04-12T22:00:00Z")
+			So(goal.ChartData[1].RangeStatus, ShouldEqual, "pending")
+			So(len(goal.Subscribers), ShouldEqual, 1)
+			So(goal.Subscribers[0].EmailFrequency, ShouldEqual, "weekly")
+		})
+		Convey("Single goal must be requested by id", func() {
+			dt := NewDummyTransport(`{"data": {"id": "f2b7b1b0-3a52-4c3e-8b4b-3a0b1c9c8f12", "title": "Code 10 hrs per week in Go"}}`)
+			wt := New(dt)
+			g, err := wt.Goal(context.Background(), CurrentUser, "f2b7b1b0-3a52-4c3e-8b4b-3a0b1c9c8f12")
+			So(err, ShouldBeNil)
+			So(g.Data.Title, ShouldEqual, "Code 10 hrs per week in Go")
+			So(dt.req.URL.Path, ShouldEqual, "/api/v1/users/current/goals/f2b7b1b0-3a52-4c3e-8b4b-3a0b1c9c8f12")
+		})
+	})
+	Convey("Given parsed goal", t, func() {
+		wt := New(NewDummyTransport(goals))
+		g, err := wt.Goals(context.Background(), CurrentUser)
+		So(err, ShouldBeNil)
+		goal := g.Data[0]
+		weekStart := time.Date(2015, 4, 19, 22, 0, 0, 0, time.UTC)
+		Convey("Goal must be on track early in the week", func() {
+			p, err := goal.Progress(weekStart.Add(24 * time.Hour))
+			So(err, ShouldBeNil)
+			So(p.Status, ShouldEqual, GoalOnTrack)
+			So(p.Period.Range.Date, ShouldEqual, "2015-04-20")
+			So(p.Percent, ShouldEqual, 40)
+		})
+		Convey("Goal must be behind late in the week", func() {
+			p, err := goal.Progress(weekStart.Add(6 * 24 * time.Hour))
+			So(err, ShouldBeNil)
+			So(p.Status, ShouldEqual, GoalBehind)
+			So(p.ExpectedSeconds, ShouldBeGreaterThan, p.ActualSeconds)
+		})
+		Convey("Goal must be reached in the previous week", func() {
+			p, err := goal.Progress(weekStart.Add(-24 * time.Hour))
+			So(err, ShouldBeNil)
+			So(p.Status, ShouldEqual, GoalReached)
+			So(p.Period.Range.Date, ShouldEqual, "2015-04-13")
+		})
+		Convey("Inverse goal must be behind when exceeded", func() {
+			goal.IsInverse = true
+			p, err := goal.Progress(weekStart.Add(-24 * time.Hour))
+			So(err, ShouldBeNil)
+			So(p.Status, ShouldEqual, GoalBehind)
+			p, err = goal.Progress(weekStart.Add(24 * time.Hour))
+			So(err, ShouldBeNil)
+			So(p.Status, ShouldEqual, GoalOnTrack)
+		})
+		Convey("Goal without chart data must fail", func() {
+			goal.ChartData = nil
+			_, err := goal.Progress(weekStart)
+			So(err, ShouldEqual, ErrNoGoalData)
+		})
+	})
+}
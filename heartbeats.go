@@ -0,0 +1,159 @@
+package wakatime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// MaxBulkHeartbeats is the maximum number of heartbeats accepted by the API in
+// a single bulk request
+const MaxBulkHeartbeats = 25
+
+// HeartbeatResult is the result of a single heartbeat in a bulk request
+type HeartbeatResult struct {
+	// StatusCode is the HTTP status code for the heartbeat
+	StatusCode int
+	// Data contains the created heartbeat on success
+	Data *HeartbeatItem
+	// Error contains the error message returned for the heartbeat
+	Error string
+}
+
+// heartbeatResponse is the body of the response for a single heartbeat
+type heartbeatResponse struct {
+	Data   *HeartbeatItem
+	Error  string
+	Errors json.RawMessage
+}
+
+// bulkResponse is the body of the bulk endpoints' response where each item is
+// a [body, status code] pair
+type bulkResponse struct {
+	Responses [][]json.RawMessage
+}
+
+// bulkItem is single item of the bulk response
+type bulkItem struct {
+	StatusCode int
+	Body       json.RawMessage
+}
+
+// SendHeartbeat sends single heartbeat for the user
+func (wt *WakaTime) SendHeartbeat(ctx context.Context, user string, hb HeartbeatItem) (*HeartbeatItem, error) {
+	var err error
+	var u *url.URL
+	if u, err = wt.apiURL("users", user, "heartbeats"); err != nil {
+		return nil, err
+	}
+	var content []byte
+	if content, err = wt.sendURL(ctx, http.MethodPost, u.String(), hb); err != nil {
+		return nil, err
+	}
+	var hr heartbeatResponse
+	if err = json.Unmarshal(content, &hr); err != nil {
+		return nil, err
+	}
+	return hr.Data, nil
+}
+
+// SendHeartbeats sends the heartbeats for the user using the bulk endpoint.
+// Heartbeats are split in chunks of MaxBulkHeartbeats and the results are
+// returned in the order of the heartbeats.
+func (wt *WakaTime) SendHeartbeats(ctx context.Context, user string, hbs []HeartbeatItem) ([]HeartbeatResult, error) {
+	var err error
+	var u *url.URL
+	if u, err = wt.apiURL("users", user, "heartbeats.bulk"); err != nil {
+		return nil, err
+	}
+	results := make([]HeartbeatResult, 0, len(hbs))
+	for start := 0; start < len(hbs); start += MaxBulkHeartbeats {
+		end := start + MaxBulkHeartbeats
+		if end > len(hbs) {
+			end = len(hbs)
+		}
+		var content []byte
+		if content, err = wt.sendURL(ctx, http.MethodPost, u.String(), hbs[start:end]); err != nil {
+			return results, err
+		}
+		var chunk []HeartbeatResult
+		if chunk, err = parseBulkHeartbeatsResponse(content); err != nil {
+			return results, err
+		}
+		results = append(results, chunk...)
+	}
+	return results, nil
+}
+
+// deleteHeartbeatsRequest is the body of the bulk delete request
+type deleteHeartbeatsRequest struct {
+	Date string   `json:"date"`
+	IDs  []string `json:"ids"`
+}
+
+// DeleteHeartbeats deletes the heartbeats with the given ids from the user's
+// heartbeats for the given day
+func (wt *WakaTime) DeleteHeartbeats(ctx context.Context, user string, date time.Time, ids []string) error {
+	var err error
+	var u *url.URL
+	if u, err = wt.apiURL("users", user, "heartbeats.bulk"); err != nil {
+		return err
+	}
+	_, err = wt.sendURL(ctx, http.MethodDelete, u.String(), deleteHeartbeatsRequest{
+		Date: date.Format("2006-01-02"),
+		IDs:  ids,
+	})
+	return err
+}
+
+func parseBulkHeartbeatsResponse(content []byte) ([]HeartbeatResult, error) {
+	items, err := parseBulkResponse(content)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]HeartbeatResult, len(items))
+	for i, item := range items {
+		var hr heartbeatResponse
+		if err := json.Unmarshal(item.Body, &hr); err != nil {
+			return nil, err
+		}
+		results[i] = HeartbeatResult{
+			StatusCode: item.StatusCode,
+			Data:       hr.Data,
+			Error:      errorMessage(hr.Error, hr.Errors),
+		}
+	}
+	return results, nil
+}
+
+func parseBulkResponse(content []byte) ([]bulkItem, error) {
+	var br bulkResponse
+	if err := json.Unmarshal(content, &br); err != nil {
+		return nil, err
+	}
+	items := make([]bulkItem, len(br.Responses))
+	for i, r := range br.Responses {
+		if len(r) != 2 {
+			return nil, fmt.Errorf("unexpected bulk response: %d items", len(r))
+		}
+		if err := json.Unmarshal(r[1], &items[i].StatusCode); err != nil {
+			return nil, err
+		}
+		items[i].Body = r[0]
+	}
+	return items, nil
+}
+
+// errorMessage returns the first error message from the error and errors fields
+func errorMessage(msg string, errs json.RawMessage) string {
+	if msg != "" {
+		return msg
+	}
+	if msgs := parseErrorMessages(errs); len(msgs) > 0 {
+		return msgs[0]
+	}
+	return ""
+}
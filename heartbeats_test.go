@@ -0,0 +1,107 @@
+package wakatime
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// HeartbeatsTransport echoes the sent heartbeats back like the API does
+type HeartbeatsTransport struct {
+	reqs   []*http.Request
+	bodies [][]byte
+}
+
+func (ht *HeartbeatsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, _ := ioutil.ReadAll(req.Body)
+	ht.reqs = append(ht.reqs, req)
+	ht.bodies = append(ht.bodies, body)
+	var content []byte
+	statusCode := http.StatusCreated
+	var hbs []HeartbeatItem
+	if err := json.Unmarshal(body, &hbs); err == nil {
+		responses := make([][]interface{}, len(hbs))
+		for i, hb := range hbs {
+			if hb.Entity == "" {
+				responses[i] = []interface{}{map[string]interface{}{"errors": map[string][]string{"entity": {"This field is required."}}}, 400}
+				continue
+			}
+			responses[i] = []interface{}{map[string]interface{}{"data": hb}, 201}
+		}
+		content, _ = json.Marshal(map[string]interface{}{"responses": responses})
+		statusCode = http.StatusAccepted
+	} else {
+		content = []byte(`{"data": ` + string(body) + `}`)
+	}
+	return &http.Response{
+		StatusCode: statusCode,
+		Body:       ioutil.NopCloser(bytes.NewBuffer(content)),
+	}, nil
+}
+
+func TestHeartbeats(t *testing.T) {
+	Convey("Given wakatime", t, func() {
+		ht := &HeartbeatsTransport{}
+		wt := New(ht)
+		hb := HeartbeatItem{
+			Entity:    "/home/aquilax/projects/go-wakatime/wakatime.go",
+			Type:      "file",
+			Category:  "coding",
+			Time:      1433217822.482732,
+			Project:   "go-wakatime",
+			Language:  "Go",
+			IsWrite:   true,
+			Machine:   "workstation",
+			UserAgent: "wakatime/13.0.7 vim-wakatime/4.0.0",
+		}
+		Convey("Single heartbeat must be sent", func() {
+			res, err := wt.SendHeartbeat(context.Background(), CurrentUser, hb)
+			So(err, ShouldBeNil)
+			So(res, ShouldResemble, &hb)
+			So(ht.reqs[0].Method, ShouldEqual, http.MethodPost)
+			So(ht.reqs[0].URL.String(), ShouldEqual, "https://wakatime.com/api/v1/users/current/heartbeats")
+			So(ht.reqs[0].Header.Get("Content-Type"), ShouldEqual, "application/json")
+			So(string(ht.bodies[0]), ShouldEqual, `{"entity":"/home/aquilax/projects/go-wakatime/wakatime.go","type":"file","category":"coding","time":1433217822.482732,"project":"go-wakatime","language":"Go","is_write":true,"is_debugging":false,"machine":"workstation","user_agent":"wakatime/13.0.7 vim-wakatime/4.0.0"}`)
+		})
+		Convey("Bulk heartbeats must be sent in chunks", func() {
+			hbs := make([]HeartbeatItem, 60)
+			for i := range hbs {
+				hbs[i] = hb
+				hbs[i].Time += float64(i)
+			}
+			hbs[30].Entity = ""
+			res, err := wt.SendHeartbeats(context.Background(), CurrentUser, hbs)
+			So(err, ShouldBeNil)
+			So(len(ht.reqs), ShouldEqual, 3)
+			So(ht.reqs[0].URL.String(), ShouldEqual, "https://wakatime.com/api/v1/users/current/heartbeats.bulk")
+			So(len(res), ShouldEqual, 60)
+			So(res[0].StatusCode, ShouldEqual, 201)
+			So(res[59].Data.Time, ShouldEqual, hb.Time+59)
+			So(res[30].StatusCode, ShouldEqual, 400)
+			So(res[30].Data, ShouldBeNil)
+			So(res[30].Error, ShouldEqual, "entity: This field is required.")
+		})
+		Convey("Heartbeats must be deleted", func() {
+			dt := NewDummyTransport("")
+			wt := New(dt)
+			err := wt.DeleteHeartbeats(context.Background(), CurrentUser, time.Date(2015, 6, 2, 0, 0, 0, 0, time.UTC), []string{"2727163e-e614-47cd-8ffc-f3dc3f18bcdc"})
+			So(err, ShouldBeNil)
+			So(dt.req.Method, ShouldEqual, http.MethodDelete)
+			So(dt.req.URL.String(), ShouldEqual, "https://wakatime.com/api/v1/users/current/heartbeats.bulk")
+			body, _ := ioutil.ReadAll(dt.req.Body)
+			So(string(body), ShouldEqual, `{"date":"2015-06-02","ids":["2727163e-e614-47cd-8ffc-f3dc3f18bcdc"]}`)
+		})
+		Convey("Empty bulk must not send requests", func() {
+			res, err := wt.SendHeartbeats(context.Background(), CurrentUser, nil)
+			So(err, ShouldBeNil)
+			So(res, ShouldBeEmpty)
+			So(ht.reqs, ShouldBeEmpty)
+		})
+	})
+}
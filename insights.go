@@ -0,0 +1,138 @@
+package wakatime
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+)
+
+// InsightType is the kind of insight
+type InsightType string
+
+// Insight types
+const (
+	InsightWeekday          InsightType = "weekday"
+	InsightDays             InsightType = "days"
+	InsightBestDay          InsightType = "best_day"
+	InsightDailyAverage     InsightType = "daily_average"
+	InsightLanguages        InsightType = "languages"
+	InsightEditors          InsightType = "editors"
+	InsightProjects         InsightType = "projects"
+	InsightCategories       InsightType = "categories"
+	InsightMachines         InsightType = "machines"
+	InsightOperatingSystems InsightType = "operating_systems"
+)
+
+// InsightWeekdayItem contains the time spent on single day of the week
+type InsightWeekdayItem struct {
+	Average              float64
+	Count                int
+	HumanReadableAverage string `json:"human_readable_average"`
+	HumanReadableTotal   string `json:"human_readable_total"`
+	Name                 string
+	TotalSeconds         float64 `json:"total_seconds"`
+}
+
+// InsightDayItem contains the time spent on single date
+type InsightDayItem struct {
+	Date  string
+	Total float64
+}
+
+// InsightBestDayItem contains the day with the most time spent
+type InsightBestDayItem struct {
+	Date         string
+	Text         string
+	TotalSeconds float64 `json:"total_seconds"`
+}
+
+// InsightDailyAverageItem contains the daily average for the range
+type InsightDailyAverageItem struct {
+	DailyAverage                                    float64 `json:"daily_average"`
+	DailyAverageIncludingOtherLanguage              float64 `json:"daily_average_including_other_language"`
+	DaysIncludingHolidays                           int     `json:"days_including_holidays"`
+	DaysMinusHolidays                               int     `json:"days_minus_holidays"`
+	Holidays                                        int
+	HumanReadableDailyAverage                       string `json:"human_readable_daily_average"`
+	HumanReadableDailyAverageIncludingOtherLanguage string `json:"human_readable_daily_average_including_other_language"`
+}
+
+// InsightItem contains the time spent on single language, editor, project,
+// category, machine or operating system
+type InsightItem struct {
+	Name         string
+	TotalSeconds float64 `json:"total_seconds"`
+}
+
+// InsightData contains single insight. Only the payload field matching Type
+// is set.
+type InsightData struct {
+	// Type is the requested insight type
+	Type               InsightType `json:"-"`
+	End                Time
+	HumanReadableRange string `json:"human_readable_range"`
+	IsIncludingToday   bool   `json:"is_including_today"`
+	IsUpToDate         bool   `json:"is_up_to_date"`
+	PercentCalculated  int    `json:"percent_calculated"`
+	Range              Range
+	Start              Time
+	Status             string
+	Timeout            int
+	UserID             string `json:"user_id"`
+	WritesOnly         bool   `json:"writes_only"`
+
+	Weekdays         []InsightWeekdayItem
+	Days             []InsightDayItem
+	BestDay          *InsightBestDayItem      `json:"best_day"`
+	DailyAverage     *InsightDailyAverageItem `json:"daily_average"`
+	Languages        []InsightItem
+	Editors          []InsightItem
+	Projects         []InsightItem
+	Categories       []InsightItem
+	Machines         []InsightItem
+	OperatingSystems []InsightItem `json:"operating_systems"`
+}
+
+// Insight contains the insight report
+type Insight struct {
+	Data InsightData
+}
+
+// Insights fetches the insight of the given type for the range
+func (wt *WakaTime) Insights(ctx context.Context, user string, insight InsightType, rng Range) (*Insight, error) {
+	var err error
+	var u *url.URL
+	if u, err = wt.apiURL("users", user, "insights", string(insight), rng.String()); err != nil {
+		return nil, err
+	}
+	var content []byte
+	if content, err = wt.fetchURL(ctx, u.String()); err != nil {
+		return nil, err
+	}
+	var in Insight
+	if err = json.Unmarshal(content, &in); err != nil {
+		return nil, err
+	}
+	in.Data.Type = insight
+	return &in, nil
+}
+
+// Items returns the name and time items for the languages, editors, projects,
+// categories, machines and operating systems insights
+func (d *InsightData) Items() []InsightItem {
+	switch d.Type {
+	case InsightLanguages:
+		return d.Languages
+	case InsightEditors:
+		return d.Editors
+	case InsightProjects:
+		return d.Projects
+	case InsightCategories:
+		return d.Categories
+	case InsightMachines:
+		return d.Machines
+	case InsightOperatingSystems:
+		return d.OperatingSystems
+	}
+	return nil
+}
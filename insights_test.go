@@ -0,0 +1,87 @@
+package wakatime
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func fetchInsightFixture(insight InsightType) (*Insight, *DummyTransport, error) {
+	content, err := ioutil.ReadFile(filepath.Join("testdata", "insights", string(insight)+".json"))
+	if err != nil {
+		return nil, nil, err
+	}
+	dt := NewDummyTransport(string(content))
+	in, err := New(dt).Insights(context.Background(), CurrentUser, insight, Last7Days)
+	return in, dt, err
+}
+
+func TestInsights(t *testing.T) {
+	Convey("Given insight fixtures", t, func() {
+		Convey("Common fields must be correctly parsed", func() {
+			in, dt, err := fetchInsightFixture(InsightWeekday)
+			So(err, ShouldBeNil)
+			So(dt.req.URL.Path, ShouldEqual, "/api/v1/users/current/insights/weekday/last_7_days")
+			So(in.Data.Type, ShouldEqual, InsightWeekday)
+			So(in.Data.Range, ShouldEqual, Last7Days)
+			So(in.Data.Status, ShouldEqual, "ok")
+			So(in.Data.IsUpToDate, ShouldBeTrue)
+			So(in.Data.PercentCalculated, ShouldEqual, 100)
+			So(in.Data.HumanReadableRange, ShouldEqual, "last 7 days")
+			So(in.Data.Start.Time().UTC().Format(time.RFC3339), ShouldEqual, "2015-04-21T22:00:00Z")
+			So(in.Data.End.Time().UTC().Format(time.RFC3339), ShouldEqual, "2015-04-28T21:59:59Z")
+		})
+		Convey("Weekday insight must be correctly parsed", func() {
+			in, _, err := fetchInsightFixture(InsightWeekday)
+			So(err, ShouldBeNil)
+			So(len(in.Data.Weekdays), ShouldEqual, 2)
+			So(in.Data.Weekdays[0].Name, ShouldEqual, "Monday")
+			So(in.Data.Weekdays[0].Average, ShouldEqual, 7405.5)
+			So(in.Data.Weekdays[0].Count, ShouldEqual, 2)
+			So(in.Data.Weekdays[0].HumanReadableTotal, ShouldEqual, "4 hrs 6 mins")
+			So(in.Data.Items(), ShouldBeNil)
+		})
+		Convey("Days insight must be correctly parsed", func() {
+			in, _, err := fetchInsightFixture(InsightDays)
+			So(err, ShouldBeNil)
+			So(in.Data.Days, ShouldResemble, []InsightDayItem{{"2015-04-22", 11165.5}, {"2015-04-23", 0}})
+		})
+		Convey("Best day insight must be correctly parsed", func() {
+			in, _, err := fetchInsightFixture(InsightBestDay)
+			So(err, ShouldBeNil)
+			So(in.Data.BestDay, ShouldResemble, &InsightBestDayItem{Date: "2015-04-22", Text: "3 hrs 6 mins", TotalSeconds: 11165.5})
+			So(in.Data.DailyAverage, ShouldBeNil)
+		})
+		Convey("Daily average insight must be correctly parsed", func() {
+			in, _, err := fetchInsightFixture(InsightDailyAverage)
+			So(err, ShouldBeNil)
+			So(in.Data.DailyAverage.DailyAverage, ShouldEqual, 7405)
+			So(in.Data.DailyAverage.DaysMinusHolidays, ShouldEqual, 5)
+			So(in.Data.DailyAverage.Holidays, ShouldEqual, 2)
+			So(in.Data.DailyAverage.HumanReadableDailyAverageIncludingOtherLanguage, ShouldEqual, "2 hrs 5 mins")
+			So(in.Data.BestDay, ShouldBeNil)
+		})
+		Convey("Item insights must be correctly parsed", func() {
+			expected := map[InsightType]string{
+				InsightLanguages:        "Go",
+				InsightEditors:          "Vim",
+				InsightProjects:         "go-wakatime",
+				InsightCategories:       "Coding",
+				InsightMachines:         "workstation",
+				InsightOperatingSystems: "Linux",
+			}
+			for insight, name := range expected {
+				in, _, err := fetchInsightFixture(insight)
+				So(err, ShouldBeNil)
+				items := in.Data.Items()
+				So(len(items), ShouldEqual, 2)
+				So(items[0], ShouldResemble, InsightItem{Name: name, TotalSeconds: 21569.5})
+				So(in.Data.Weekdays, ShouldBeEmpty)
+			}
+		})
+	})
+}
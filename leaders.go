@@ -0,0 +1,234 @@
+package wakatime
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// LeaderboardLanguage contains the time spent in single language
+type LeaderboardLanguage struct {
+	Name         string
+	TotalSeconds float64 `json:"total_seconds"`
+}
+
+// LeaderboardRunningTotal contains the user's totals for the leaderboard range
+type LeaderboardRunningTotal struct {
+	DailyAverage              float64 `json:"daily_average"`
+	HumanReadableDailyAverage string  `json:"human_readable_daily_average"`
+	HumanReadableTotal        string  `json:"human_readable_total"`
+	Languages                 []LeaderboardLanguage
+	TotalSeconds              float64 `json:"total_seconds"`
+}
+
+// LeaderboardCity contains the user's location
+type LeaderboardCity struct {
+	CountryCode string `json:"country_code"`
+	Name        string
+	State       string
+	Title       string
+}
+
+// LeaderboardUser contains the public user data in the leaderboard
+type LeaderboardUser struct {
+	City                 *LeaderboardCity
+	DisplayName          string `json:"display_name"`
+	Email                string
+	FullName             string `json:"full_name"`
+	HumanReadableWebsite string `json:"human_readable_website"`
+	ID                   string
+	IsEmailPublic        bool `json:"is_email_public"`
+	IsHireable           bool `json:"is_hireable"`
+	Location             string
+	Photo                string
+	PhotoPublic          bool `json:"photo_public"`
+	Username             string
+	Website              string
+}
+
+// LeaderboardRank contains single position in the leaderboard
+type LeaderboardRank struct {
+	Rank         int
+	RunningTotal LeaderboardRunningTotal `json:"running_total"`
+	User         LeaderboardUser
+}
+
+// LeaderboardCurrentUser contains the position of the current user
+type LeaderboardCurrentUser struct {
+	Page int
+	LeaderboardRank
+}
+
+// LeadersRange contains the range of the leaderboard
+type LeadersRange struct {
+	EndDate   string `json:"end_date"`
+	EndText   string `json:"end_text"`
+	Name      string
+	StartDate string `json:"start_date"`
+	StartText string `json:"start_text"`
+	Text      string
+}
+
+// Leaders contains single page of a leaderboard
+type Leaders struct {
+	CountryCode string                  `json:"country_code"`
+	CurrentUser *LeaderboardCurrentUser `json:"current_user"`
+	Data        []LeaderboardRank
+	Language    string
+	ModifiedAt  time.Time `json:"modified_at"`
+	Page        int
+	Range       LeadersRange
+	Timeout     int
+	TotalPages  int  `json:"total_pages"`
+	WritesOnly  bool `json:"writes_only"`
+}
+
+// PrivateLeaderboardData contains single private leaderboard
+type PrivateLeaderboardData struct {
+	CanDelete                 bool      `json:"can_delete"`
+	CanEdit                   bool      `json:"can_edit"`
+	CreatedAt                 time.Time `json:"created_at"`
+	HasAvailableSeat          bool      `json:"has_available_seat"`
+	ID                        string
+	MembersCount              int        `json:"members_count"`
+	MembersWithTimezonesCount int        `json:"members_with_timezones_count"`
+	ModifiedAt                *time.Time `json:"modified_at"`
+	Name                      string
+	TimeRange                 string `json:"time_range"`
+}
+
+// PrivateLeaderboards contains the user's private leaderboards
+type PrivateLeaderboards struct {
+	Data       []PrivateLeaderboardData
+	Total      int
+	TotalPages int `json:"total_pages"`
+}
+
+// LeadersOptions contains the filters for the leaderboards
+type LeadersOptions struct {
+	// Language limits the leaderboard to single language
+	Language string
+	// CountryCode limits the leaderboard to users from the country
+	CountryCode string
+	// IsHireable limits the leaderboard to users looking for work
+	IsHireable *bool
+	// Page is the page number, starting from 1
+	Page int
+}
+
+// Values encodes the options as query parameters
+func (o *LeadersOptions) Values() url.Values {
+	q := url.Values{}
+	if o == nil {
+		return q
+	}
+	if o.Language != "" {
+		q.Set("language", o.Language)
+	}
+	if o.CountryCode != "" {
+		q.Set("country_code", o.CountryCode)
+	}
+	if o.IsHireable != nil {
+		q.Set("is_hireable", strconv.FormatBool(*o.IsHireable))
+	}
+	if o.Page > 0 {
+		q.Set("page", strconv.Itoa(o.Page))
+	}
+	return q
+}
+
+// Leaders fetches single page of the public leaderboard
+func (wt *WakaTime) Leaders(ctx context.Context, opts *LeadersOptions) (*Leaders, error) {
+	var err error
+	var u *url.URL
+	if u, err = wt.apiURL("leaders"); err != nil {
+		return nil, err
+	}
+	u.RawQuery = opts.Values().Encode()
+	return wt.fetchLeaders(ctx, u)
+}
+
+// PrivateLeaderboards fetches the user's private leaderboards
+func (wt *WakaTime) PrivateLeaderboards(ctx context.Context, user string) (*PrivateLeaderboards, error) {
+	var err error
+	var u *url.URL
+	if u, err = wt.apiURL("users", user, "leaderboards"); err != nil {
+		return nil, err
+	}
+	var content []byte
+	if content, err = wt.fetchURL(ctx, u.String()); err != nil {
+		return nil, err
+	}
+	var pl PrivateLeaderboards
+	if err = json.Unmarshal(content, &pl); err != nil {
+		return nil, err
+	}
+	return &pl, nil
+}
+
+// PrivateLeaderboard fetches single page of the members ranking in the user's
+// private leaderboard
+func (wt *WakaTime) PrivateLeaderboard(ctx context.Context, user, board string, opts *LeadersOptions) (*Leaders, error) {
+	var err error
+	var u *url.URL
+	if u, err = wt.apiURL("users", user, "leaderboards", board); err != nil {
+		return nil, err
+	}
+	u.RawQuery = opts.Values().Encode()
+	return wt.fetchLeaders(ctx, u)
+}
+
+// WalkLeaders calls fn for each page of the public leaderboard starting from
+// opts.Page. Walking stops at the last page or when fn returns an error.
+func (wt *WakaTime) WalkLeaders(ctx context.Context, opts *LeadersOptions, fn func(*Leaders) error) error {
+	return walkLeaders(opts, func(o *LeadersOptions) (*Leaders, error) {
+		return wt.Leaders(ctx, o)
+	}, fn)
+}
+
+// WalkPrivateLeaderboard calls fn for each page of the private leaderboard
+// starting from opts.Page. Walking stops at the last page or when fn returns an
+// error.
+func (wt *WakaTime) WalkPrivateLeaderboard(ctx context.Context, user, board string, opts *LeadersOptions, fn func(*Leaders) error) error {
+	return walkLeaders(opts, func(o *LeadersOptions) (*Leaders, error) {
+		return wt.PrivateLeaderboard(ctx, user, board, o)
+	}, fn)
+}
+
+func walkLeaders(opts *LeadersOptions, fetch func(*LeadersOptions) (*Leaders, error), fn func(*Leaders) error) error {
+	o := LeadersOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Page < 1 {
+		o.Page = 1
+	}
+	for {
+		l, err := fetch(&o)
+		if err != nil {
+			return err
+		}
+		if err = fn(l); err != nil {
+			return err
+		}
+		// advance from the requested page, the response may leave it out
+		if o.Page >= l.TotalPages || len(l.Data) == 0 {
+			return nil
+		}
+		o.Page++
+	}
+}
+
+func (wt *WakaTime) fetchLeaders(ctx context.Context, u *url.URL) (*Leaders, error) {
+	content, err := wt.fetchURL(ctx, u.String())
+	if err != nil {
+		return nil, err
+	}
+	var l Leaders
+	if err = json.Unmarshal(content, &l); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
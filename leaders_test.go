@@ -0,0 +1,183 @@
+package wakatime
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const leaders = `{
+  "country_code": "SE",
+  "current_user": {
+    "page": 2,
+    "rank": 3,
+    "running_total": {
+      "daily_average": 7405.7,
+      "human_readable_daily_average": "2 hrs 3 mins",
+      "human_readable_total": "14 hrs 24 mins",
+      "languages": [{"name": "Go", "total_seconds": 21569}],
+      "total_seconds": 51840
+    },
+    "user": {"id": "e9b45851-991b-4755-ffff-6355d927f472", "username": "aquilax"}
+  },
+  "data": [
+    {
+      "rank": %d,
+      "running_total": {
+        "daily_average": 10800,
+        "human_readable_daily_average": "3 hrs",
+        "human_readable_total": "21 hrs",
+        "languages": [{"name": "Go", "total_seconds": 50400}, {"name": "Python", "total_seconds": 25200}],
+        "total_seconds": 75600
+      },
+      "user": {
+        "city": {"country_code": "SE", "name": "Stockholm", "state": "Stockholm", "title": "Stockholm, Sweden"},
+        "display_name": "gopher",
+        "full_name": "Go Pher",
+        "id": "0b7e6f6a-1c2d-4e5f-8a9b-0c1d2e3f4a5b",
+        "is_hireable": true,
+        "username": "gopher"
+      }
+    }
+  ],
+  "language": "Go",
+  "modified_at": "2015-04-28T07:08:06Z",
+  "page": %d,
+  "range": {
+    "end_date": "2015-04-27",
+    "end_text": "Mon Apr 27th 2015",
+    "name": "last_7_days",
+    "start_date": "2015-04-21",
+    "start_text": "Tue Apr 21st 2015",
+    "text": "Last 7 Days"
+  },
+  "timeout": 15,
+  "total_pages": 3,
+  "writes_only": false
+}`
+
+// PagesTransport responds with the leaders page requested in the query. With
+// omitPage the page is left out of the response like some compatible servers do.
+type PagesTransport struct {
+	reqs     []*http.Request
+	omitPage bool
+}
+
+func (pt *PagesTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	pt.reqs = append(pt.reqs, req)
+	var page int
+	fmt.Sscan(req.URL.Query().Get("page"), &page)
+	if page == 0 {
+		page = 1
+	}
+	content := fmt.Sprintf(leaders, page*10, page)
+	if pt.omitPage {
+		content = strings.Replace(content, fmt.Sprintf("\n  \"page\": %d,", page), "", 1)
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(content)),
+	}, nil
+}
+
+func TestLeaders(t *testing.T) {
+	Convey("Given wakatime", t, func() {
+		pt := &PagesTransport{}
+		wt := New(pt)
+		Convey("Leaders JSON must be correctly parsed", func() {
+			hireable := true
+			l, err := wt.Leaders(context.Background(), &LeadersOptions{Language: "Go", CountryCode: "SE", IsHireable: &hireable, Page: 2})
+			So(err, ShouldBeNil)
+			So(pt.reqs[0].URL.Path, ShouldEqual, "/api/v1/leaders")
+			So(pt.reqs[0].URL.RawQuery, ShouldEqual, "country_code=SE&is_hireable=true&language=Go&page=2")
+			So(l.Page, ShouldEqual, 2)
+			So(l.TotalPages, ShouldEqual, 3)
+			So(l.Language, ShouldEqual, "Go")
+			So(l.CountryCode, ShouldEqual, "SE")
+			So(l.Range.Name, ShouldEqual, "last_7_days")
+			So(l.CurrentUser.Rank, ShouldEqual, 3)
+			So(l.CurrentUser.Page, ShouldEqual, 2)
+			So(l.CurrentUser.User.Username, ShouldEqual, "aquilax")
+			So(len(l.Data), ShouldEqual, 1)
+			So(l.Data[0].Rank, ShouldEqual, 20)
+			So(l.Data[0].RunningTotal.TotalSeconds, ShouldEqual, 75600)
+			So(l.Data[0].RunningTotal.HumanReadableDailyAverage, ShouldEqual, "3 hrs")
+			So(len(l.Data[0].RunningTotal.Languages), ShouldEqual, 2)
+			So(l.Data[0].User.City.Title, ShouldEqual, "Stockholm, Sweden")
+			So(l.Data[0].User.IsHireable, ShouldBeTrue)
+		})
+		Convey("Private leaderboard must use the board path", func() {
+			_, err := wt.PrivateLeaderboard(context.Background(), CurrentUser, "5f3d9a1c", nil)
+			So(err, ShouldBeNil)
+			So(pt.reqs[0].URL.Path, ShouldEqual, "/api/v1/users/current/leaderboards/5f3d9a1c")
+		})
+		Convey("Walker must visit all pages", func() {
+			var ranks []int
+			err := wt.WalkLeaders(context.Background(), &LeadersOptions{Language: "Go"}, func(l *Leaders) error {
+				ranks = append(ranks, l.Data[0].Rank)
+				return nil
+			})
+			So(err, ShouldBeNil)
+			So(ranks, ShouldResemble, []int{10, 20, 30})
+			So(len(pt.reqs), ShouldEqual, 3)
+			So(pt.reqs[2].URL.RawQuery, ShouldEqual, "language=Go&page=3")
+		})
+		Convey("Walker must advance from the requested page when the response has none", func() {
+			pt.omitPage = true
+			var ranks []int
+			err := wt.WalkLeaders(context.Background(), nil, func(l *Leaders) error {
+				So(l.Page, ShouldEqual, 0)
+				ranks = append(ranks, l.Data[0].Rank)
+				return nil
+			})
+			So(err, ShouldBeNil)
+			So(ranks, ShouldResemble, []int{10, 20, 30})
+			So(len(pt.reqs), ShouldEqual, 3)
+		})
+		Convey("Walker must stop on callback error", func() {
+			stop := errors.New("stop")
+			err := wt.WalkPrivateLeaderboard(context.Background(), CurrentUser, "5f3d9a1c", nil, func(l *Leaders) error {
+				return stop
+			})
+			So(err, ShouldEqual, stop)
+			So(len(pt.reqs), ShouldEqual, 1)
+		})
+	})
+	Convey("Given wakatime with private leaderboards", t, func() {
+		dt := NewDummyTransport(`{
+  "data": [
+    {
+      "can_delete": true,
+      "can_edit": true,
+      "created_at": "2015-04-01T10:00:00Z",
+      "has_available_seat": true,
+      "id": "5f3d9a1c",
+      "members_count": 12,
+      "members_with_timezones_count": 10,
+      "modified_at": null,
+      "name": "Office",
+      "time_range": "last_7_days"
+    }
+  ],
+  "total": 1,
+  "total_pages": 1
+}`)
+		wt := New(dt)
+		Convey("Private leaderboards JSON must be correctly parsed", func() {
+			pl, err := wt.PrivateLeaderboards(context.Background(), CurrentUser)
+			So(err, ShouldBeNil)
+			So(dt.req.URL.Path, ShouldEqual, "/api/v1/users/current/leaderboards")
+			So(pl.Total, ShouldEqual, 1)
+			So(pl.Data[0].Name, ShouldEqual, "Office")
+			So(pl.Data[0].MembersCount, ShouldEqual, 12)
+			So(pl.Data[0].TimeRange, ShouldEqual, "last_7_days")
+		})
+	})
+}
@@ -0,0 +1,174 @@
+package wakatime
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"time"
+)
+
+// UserAgentData contains single plugin user agent used by the user
+type UserAgentData struct {
+	CreatedAt          time.Time `json:"created_at"`
+	Editor             string
+	ID                 string
+	IsBrowserExtension bool       `json:"is_browser_extension"`
+	IsDesktopApp       bool       `json:"is_desktop_app"`
+	LastSeenAt         *time.Time `json:"last_seen_at"`
+	OS                 string
+	Value              string
+	Version            string
+}
+
+// UserAgents contains the user's plugin user agents
+type UserAgents struct {
+	Data       []UserAgentData
+	TotalPages int `json:"total_pages"`
+}
+
+// MachineNameData contains single machine used by the user
+type MachineNameData struct {
+	CreatedAt  time.Time `json:"created_at"`
+	ID         string
+	IP         string
+	LastSeenAt *time.Time `json:"last_seen_at"`
+	Name       string
+	Timezone   string
+	Value      string
+}
+
+// MachineNames contains the user's machines
+type MachineNames struct {
+	Data       []MachineNameData
+	TotalPages int `json:"total_pages"`
+}
+
+// EditorData contains single editor supported by WakaTime
+type EditorData struct {
+	Color      string
+	HistoryURL string `json:"history_url"`
+	ID         string
+	Name       string
+	ReleasedAt *time.Time `json:"released_at"`
+	Repository string
+	Version    string
+	VersionURL string `json:"version_url"`
+	Website    string
+}
+
+// Editors contains the editors supported by WakaTime
+type Editors struct {
+	Data  []EditorData
+	Total int
+}
+
+// ProgramLanguageData contains single programming language known to WakaTime
+type ProgramLanguageData struct {
+	Color      string
+	CreatedAt  time.Time `json:"created_at"`
+	ID         string
+	IsVerified bool       `json:"is_verified"`
+	ModifiedAt *time.Time `json:"modified_at"`
+	Name       string
+}
+
+// ProgramLanguages contains the programming languages known to WakaTime
+type ProgramLanguages struct {
+	Data  []ProgramLanguageData
+	Total int
+}
+
+// Lookup returns the machine with the given id as referenced by the
+// heartbeats' MachineNameID
+func (mn *MachineNames) Lookup(id string) (MachineNameData, bool) {
+	for _, m := range mn.Data {
+		if m.ID == id {
+			return m, true
+		}
+	}
+	return MachineNameData{}, false
+}
+
+// Lookup returns the user agent with the given id as referenced by the
+// heartbeats' UserAgentID
+func (ua *UserAgents) Lookup(id string) (UserAgentData, bool) {
+	for _, a := range ua.Data {
+		if a.ID == id {
+			return a, true
+		}
+	}
+	return UserAgentData{}, false
+}
+
+// UserAgents fetches the plugin user agents used by the user
+func (wt *WakaTime) UserAgents(ctx context.Context, user string) (*UserAgents, error) {
+	var err error
+	var u *url.URL
+	if u, err = wt.apiURL("users", user, "user_agents"); err != nil {
+		return nil, err
+	}
+	var content []byte
+	if content, err = wt.fetchURL(ctx, u.String()); err != nil {
+		return nil, err
+	}
+	var ua UserAgents
+	if err = json.Unmarshal(content, &ua); err != nil {
+		return nil, err
+	}
+	return &ua, nil
+}
+
+// MachineNames fetches the machines used by the user
+func (wt *WakaTime) MachineNames(ctx context.Context, user string) (*MachineNames, error) {
+	var err error
+	var u *url.URL
+	if u, err = wt.apiURL("users", user, "machine_names"); err != nil {
+		return nil, err
+	}
+	var content []byte
+	if content, err = wt.fetchURL(ctx, u.String()); err != nil {
+		return nil, err
+	}
+	var mn MachineNames
+	if err = json.Unmarshal(content, &mn); err != nil {
+		return nil, err
+	}
+	return &mn, nil
+}
+
+// Editors fetches the editors supported by WakaTime and their latest plugin
+// versions
+func (wt *WakaTime) Editors(ctx context.Context) (*Editors, error) {
+	var err error
+	var u *url.URL
+	if u, err = wt.apiURL("editors"); err != nil {
+		return nil, err
+	}
+	var content []byte
+	if content, err = wt.fetchURL(ctx, u.String()); err != nil {
+		return nil, err
+	}
+	var e Editors
+	if err = json.Unmarshal(content, &e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// ProgramLanguages fetches the programming languages known to WakaTime
+func (wt *WakaTime) ProgramLanguages(ctx context.Context) (*ProgramLanguages, error) {
+	var err error
+	var u *url.URL
+	if u, err = wt.apiURL("program_languages"); err != nil {
+		return nil, err
+	}
+	var content []byte
+	if content, err = wt.fetchURL(ctx, u.String()); err != nil {
+		return nil, err
+	}
+	var pl ProgramLanguages
+	if err = json.Unmarshal(content, &pl); err != nil {
+		return nil, err
+	}
+	return &pl, nil
+}
@@ -0,0 +1,123 @@
+package wakatime
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const (
+	userAgents = `{
+  "data": [
+    {
+      "created_at": "2015-04-23T04:38:05Z",
+      "editor": "vim",
+      "id": "7c7c51d2-2bd7-4d6e-9a3b-1fd1e5f1c0a1",
+      "is_browser_extension": false,
+      "is_desktop_app": false,
+      "last_seen_at": "2015-04-26T04:16:23Z",
+      "os": "Linux-3.19.0-x86_64",
+      "value": "wakatime/4.0.8 (Linux-3.19.0-x86_64) Python2.7.9 vim-wakatime/4.0.1",
+      "version": "4.0.1"
+    }
+  ],
+  "total_pages": 1
+}`
+	machineNames = `{
+  "data": [
+    {
+      "created_at": "2015-04-23T04:38:05Z",
+      "id": "0d7a3e2c-4c2b-4f0e-9b4a-6b1e2c3d4f5a",
+      "ip": "192.0.2.10",
+      "last_seen_at": "2015-04-26T04:16:23Z",
+      "name": "workstation",
+      "timezone": "Europe/Stockholm",
+      "value": "workstation"
+    }
+  ],
+  "total_pages": 1
+}`
+	editors = `{
+  "data": [
+    {
+      "color": "#019733",
+      "history_url": "https://github.com/wakatime/vim-wakatime/blob/master/HISTORY.rst",
+      "id": "vim",
+      "name": "Vim",
+      "released_at": "2015-04-20T10:00:00Z",
+      "repository": "https://github.com/wakatime/vim-wakatime",
+      "version": "4.0.2",
+      "version_url": "https://raw.githubusercontent.com/wakatime/vim-wakatime/master/plugin/wakatime.vim",
+      "website": "https://wakatime.com/vim"
+    }
+  ],
+  "total": 1
+}`
+	programLanguages = `{
+  "data": [
+    {
+      "color": "#00ADD8",
+      "created_at": "2015-04-23T04:38:05Z",
+      "id": "2d8b1e3a-4c5f-4d6e-8a9b-0c1d2e3f4a5b",
+      "is_verified": true,
+      "modified_at": null,
+      "name": "Go"
+    }
+  ],
+  "total": 1
+}`
+)
+
+func TestMetadata(t *testing.T) {
+	Convey("Given wakatime", t, func() {
+		Convey("User agents JSON must be correctly parsed", func() {
+			dt := NewDummyTransport(userAgents)
+			wt := New(dt)
+			ua, err := wt.UserAgents(context.Background(), CurrentUser)
+			So(err, ShouldBeNil)
+			So(dt.req.URL.Path, ShouldEqual, "/api/v1/users/current/user_agents")
+			So(ua.Data[0].Editor, ShouldEqual, "vim")
+			So(ua.Data[0].Version, ShouldEqual, "4.0.1")
+			So(ua.Data[0].OS, ShouldEqual, "Linux-3.19.0-x86_64")
+			So(ua.Data[0].LastSeenAt.Format(time.RFC3339), ShouldEqual, "2015-04-26T04:16:23Z")
+			a, ok := ua.Lookup("7c7c51d2-2bd7-4d6e-9a3b-1fd1e5f1c0a1")
+			So(ok, ShouldBeTrue)
+			So(a.Value, ShouldStartWith, "wakatime/4.0.8")
+		})
+		Convey("Machine names JSON must be correctly parsed", func() {
+			dt := NewDummyTransport(machineNames)
+			wt := New(dt)
+			mn, err := wt.MachineNames(context.Background(), CurrentUser)
+			So(err, ShouldBeNil)
+			So(dt.req.URL.Path, ShouldEqual, "/api/v1/users/current/machine_names")
+			m, ok := mn.Lookup("0d7a3e2c-4c2b-4f0e-9b4a-6b1e2c3d4f5a")
+			So(ok, ShouldBeTrue)
+			So(m.Name, ShouldEqual, "workstation")
+			So(m.IP, ShouldEqual, "192.0.2.10")
+			_, ok = mn.Lookup("unknown")
+			So(ok, ShouldBeFalse)
+		})
+		Convey("Editors JSON must be correctly parsed", func() {
+			dt := NewDummyTransport(editors)
+			wt := New(dt)
+			e, err := wt.Editors(context.Background())
+			So(err, ShouldBeNil)
+			So(dt.req.URL.Path, ShouldEqual, "/api/v1/editors")
+			So(e.Total, ShouldEqual, 1)
+			So(e.Data[0].Name, ShouldEqual, "Vim")
+			So(e.Data[0].Version, ShouldEqual, "4.0.2")
+		})
+		Convey("Program languages JSON must be correctly parsed", func() {
+			dt := NewDummyTransport(programLanguages)
+			wt := New(dt)
+			pl, err := wt.ProgramLanguages(context.Background())
+			So(err, ShouldBeNil)
+			So(dt.req.URL.Path, ShouldEqual, "/api/v1/program_languages")
+			So(pl.Data[0].Name, ShouldEqual, "Go")
+			So(pl.Data[0].IsVerified, ShouldBeTrue)
+			So(pl.Data[0].ModifiedAt, ShouldBeNil)
+		})
+	})
+}
@@ -0,0 +1,279 @@
+package wakatime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OAuth endpoints of WakaTime
+const (
+	OAuthAuthorizeURL = "https://wakatime.com/oauth/authorize"
+	OAuthTokenURL     = "https://wakatime.com/oauth/token"
+)
+
+// OAuth scopes
+const (
+	ScopeEmail                    = "email"
+	ScopeReadStats                = "read_stats"
+	ScopeReadSummaries            = "read_summaries"
+	ScopeReadHeartbeats           = "read_heartbeats"
+	ScopeWriteHeartbeats          = "write_heartbeats"
+	ScopeReadGoals                = "read_goals"
+	ScopeReadOrgs                 = "read_orgs"
+	ScopeWriteOrgs                = "write_orgs"
+	ScopeReadPrivateLeaderboards  = "read_private_leaderboards"
+	ScopeWritePrivateLeaderboards = "write_private_leaderboards"
+	ScopeReadLoggedTime           = "read_logged_time"
+	ScopeWriteLoggedTime          = "write_logged_time"
+)
+
+// tokenExpiryDelta is how early a token is refreshed before it expires
+const tokenExpiryDelta = time.Minute
+
+// ErrNoRefreshToken is returned when an expired token can not be refreshed
+var ErrNoRefreshToken = errors.New("oauth token expired and has no refresh token")
+
+// ErrNoOAuthToken is returned when OAuthTransport has no token
+var ErrNoOAuthToken = errors.New("oauth transport has no token")
+
+// OAuthConfig contains the OAuth application settings
+type OAuthConfig struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURI  string
+	Scopes       []string
+	AuthorizeURL string
+	TokenURL     string
+	// HTTPClient is used for the token requests
+	HTTPClient *http.Client
+}
+
+// OAuthToken contains the access and refresh tokens
+type OAuthToken struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	TokenType    string    `json:"token_type"`
+	Scope        string    `json:"scope"`
+	UID          string    `json:"uid"`
+	Expiry       time.Time `json:"expiry"`
+}
+
+// tokenResponse is the body of the token endpoint's JSON response
+type tokenResponse struct {
+	AccessToken  string      `json:"access_token"`
+	RefreshToken string      `json:"refresh_token"`
+	TokenType    string      `json:"token_type"`
+	Scope        string      `json:"scope"`
+	UID          string      `json:"uid"`
+	ExpiresIn    json.Number `json:"expires_in"`
+}
+
+// NewOAuthConfig creates new OAuthConfig for the WakaTime OAuth endpoints
+func NewOAuthConfig(clientID, clientSecret, redirectURI string, scopes ...string) *OAuthConfig {
+	return &OAuthConfig{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURI:  redirectURI,
+		Scopes:       scopes,
+		AuthorizeURL: OAuthAuthorizeURL,
+		TokenURL:     OAuthTokenURL,
+		HTTPClient:   http.DefaultClient,
+	}
+}
+
+// AuthCodeURL returns the URL where the user authorizes the application. The
+// state is sent back to the redirect URI and must be verified by the caller.
+func (c *OAuthConfig) AuthCodeURL(state string) string {
+	q := url.Values{}
+	q.Set("client_id", c.ClientID)
+	q.Set("response_type", "code")
+	q.Set("redirect_uri", c.RedirectURI)
+	if len(c.Scopes) > 0 {
+		q.Set("scope", strings.Join(c.Scopes, ","))
+	}
+	if state != "" {
+		q.Set("state", state)
+	}
+	sep := "?"
+	if strings.Contains(c.AuthorizeURL, "?") {
+		sep = "&"
+	}
+	return c.AuthorizeURL + sep + q.Encode()
+}
+
+// Exchange converts the authorization code to a token
+func (c *OAuthConfig) Exchange(ctx context.Context, code string) (*OAuthToken, error) {
+	return c.requestToken(ctx, url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"redirect_uri": {c.RedirectURI},
+	}, systemClock{})
+}
+
+// Refresh obtains new token using the refresh token
+func (c *OAuthConfig) Refresh(ctx context.Context, refreshToken string) (*OAuthToken, error) {
+	return c.refresh(ctx, refreshToken, systemClock{})
+}
+
+// refresh obtains new token with the expiry computed from clock
+func (c *OAuthConfig) refresh(ctx context.Context, refreshToken string, clock Clock) (*OAuthToken, error) {
+	return c.requestToken(ctx, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+		"redirect_uri":  {c.RedirectURI},
+	}, clock)
+}
+
+func (c *OAuthConfig) requestToken(ctx context.Context, form url.Values, clock Clock) (*OAuthToken, error) {
+	form.Set("client_id", c.ClientID)
+	form.Set("client_secret", c.ClientSecret)
+	var err error
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodPost, c.TokenURL, strings.NewReader(form.Encode())); err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", getUserAgent())
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	var resp *http.Response
+	if resp, err = client.Do(req); err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var content []byte
+	if content, err = ioutil.ReadAll(resp.Body); err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, newAPIError(c.TokenURL, resp, content)
+	}
+	var tr tokenResponse
+	if mt, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mt == "application/x-www-form-urlencoded" || mt == "text/plain" {
+		var v url.Values
+		if v, err = url.ParseQuery(string(content)); err != nil {
+			return nil, err
+		}
+		tr = tokenResponse{
+			AccessToken:  v.Get("access_token"),
+			RefreshToken: v.Get("refresh_token"),
+			TokenType:    v.Get("token_type"),
+			Scope:        v.Get("scope"),
+			UID:          v.Get("uid"),
+			ExpiresIn:    json.Number(v.Get("expires_in")),
+		}
+	} else if err = json.Unmarshal(content, &tr); err != nil {
+		return nil, err
+	}
+	if tr.AccessToken == "" {
+		return nil, errors.New("oauth token response has no access token")
+	}
+	t := &OAuthToken{
+		AccessToken:  tr.AccessToken,
+		RefreshToken: tr.RefreshToken,
+		TokenType:    tr.TokenType,
+		Scope:        tr.Scope,
+		UID:          tr.UID,
+	}
+	if sec, err := strconv.ParseFloat(string(tr.ExpiresIn), 64); err == nil && sec > 0 {
+		t.Expiry = clock.Now().Add(time.Duration(sec * float64(time.Second)))
+	}
+	return t, nil
+}
+
+// OAuthTransport implements http.RoundTripper and provides authentication using
+// OAuth 2.0 bearer tokens, refreshing them when they expire
+type OAuthTransport struct {
+	Config    *OAuthConfig
+	Transport http.RoundTripper
+	// OnRefresh is called with the new token after each refresh so it can be
+	// persisted. Refresh tokens are rotated and the old one stops working.
+	OnRefresh func(*OAuthToken)
+	// Clock is used to check the token expiry, the system clock is used when
+	// nil
+	Clock Clock
+	mu    sync.Mutex
+	token *OAuthToken
+}
+
+// NewOAuthTransport creates new OAuthTransport given the config and token
+func NewOAuthTransport(config *OAuthConfig, token *OAuthToken) *OAuthTransport {
+	return &OAuthTransport{
+		Config:    config,
+		Transport: http.DefaultTransport,
+		Clock:     systemClock{},
+		token:     token,
+	}
+}
+
+// Token returns copy of the current token, the zero token when there is none
+func (ot *OAuthTransport) Token() OAuthToken {
+	ot.mu.Lock()
+	defer ot.mu.Unlock()
+	if ot.token == nil {
+		return OAuthToken{}
+	}
+	return *ot.token
+}
+
+func (ot *OAuthTransport) clock() Clock {
+	if ot.Clock == nil {
+		return systemClock{}
+	}
+	return ot.Clock
+}
+
+// validToken returns the current token, refreshing it when it expires
+func (ot *OAuthTransport) validToken(ctx context.Context) (*OAuthToken, error) {
+	ot.mu.Lock()
+	defer ot.mu.Unlock()
+	if ot.token == nil {
+		return nil, ErrNoOAuthToken
+	}
+	clock := ot.clock()
+	if ot.token.Expiry.IsZero() || clock.Now().Add(tokenExpiryDelta).Before(ot.token.Expiry) {
+		return ot.token, nil
+	}
+	if ot.token.RefreshToken == "" {
+		return nil, ErrNoRefreshToken
+	}
+	t, err := ot.Config.refresh(ctx, ot.token.RefreshToken, clock)
+	if err != nil {
+		return nil, err
+	}
+	if t.RefreshToken == "" {
+		t.RefreshToken = ot.token.RefreshToken
+	}
+	ot.token = t
+	if ot.OnRefresh != nil {
+		ot.OnRefresh(t)
+	}
+	return t, nil
+}
+
+// RoundTrip implements the http.RoundTripper method
+func (ot *OAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t, err := ot.validToken(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	req = cloneRequest(req)
+	req.Header.Set("Authorization", "Bearer "+t.AccessToken)
+	req.Header.Set("Content-Type", "application/json")
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", getUserAgent())
+	}
+	return ot.Transport.RoundTrip(req)
+}
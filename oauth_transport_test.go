@@ -0,0 +1,213 @@
+package wakatime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// FakeTokenServer issues sequential tokens and rotates the refresh token on
+// every refresh
+type FakeTokenServer struct {
+	mu       sync.Mutex
+	form     bool
+	issued   int
+	refresh  string
+	requests []url.Values
+}
+
+func (fs *FakeTokenServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	fs.requests = append(fs.requests, r.PostForm)
+	if r.PostForm.Get("client_id") != "id" || r.PostForm.Get("client_secret") != "secret" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error":"invalid client"}`)
+		return
+	}
+	switch r.PostForm.Get("grant_type") {
+	case "authorization_code":
+		if r.PostForm.Get("code") != "code" {
+			http.Error(w, `{"error":"invalid code"}`, http.StatusBadRequest)
+			return
+		}
+	case "refresh_token":
+		if r.PostForm.Get("refresh_token") != fs.refresh {
+			http.Error(w, `{"error":"invalid refresh token"}`, http.StatusBadRequest)
+			return
+		}
+	default:
+		http.Error(w, `{"error":"unsupported grant type"}`, http.StatusBadRequest)
+		return
+	}
+	fs.issued++
+	fs.refresh = fmt.Sprintf("refresh%d", fs.issued)
+	access := fmt.Sprintf("access%d", fs.issued)
+	if fs.form {
+		w.Header().Set("Content-Type", "application/x-www-form-urlencoded")
+		fmt.Fprint(w, url.Values{
+			"access_token":  {access},
+			"refresh_token": {fs.refresh},
+			"token_type":    {"bearer"},
+			"scope":         {"read_stats"},
+			"uid":           {"uid"},
+			"expires_in":    {"3600"},
+		}.Encode())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"access_token":%q,"refresh_token":%q,"token_type":"bearer","scope":"read_stats,write_logged_time","uid":"uid","expires_in":3600}`, access, fs.refresh)
+}
+
+func newTestOAuthConfig(tokenURL string) *OAuthConfig {
+	c := NewOAuthConfig("id", "secret", "http://localhost/callback", ScopeReadStats, ScopeWriteLoggedTime)
+	c.TokenURL = tokenURL
+	return c
+}
+
+func TestOAuthConfig(t *testing.T) {
+	Convey("Given OAuth config", t, func() {
+		fs := &FakeTokenServer{}
+		ts := httptest.NewServer(fs)
+		defer ts.Close()
+		c := newTestOAuthConfig(ts.URL)
+		Convey("Authorize URL must contain the application settings", func() {
+			u, err := url.Parse(c.AuthCodeURL("xyz"))
+			So(err, ShouldBeNil)
+			So(u.Scheme+"://"+u.Host+u.Path, ShouldEqual, OAuthAuthorizeURL)
+			q := u.Query()
+			So(q.Get("client_id"), ShouldEqual, "id")
+			So(q.Get("response_type"), ShouldEqual, "code")
+			So(q.Get("redirect_uri"), ShouldEqual, "http://localhost/callback")
+			So(q.Get("scope"), ShouldEqual, "read_stats,write_logged_time")
+			So(q.Get("state"), ShouldEqual, "xyz")
+			So(q.Get("client_secret"), ShouldBeEmpty)
+		})
+		Convey("Code must be exchanged for JSON token", func() {
+			before := time.Now()
+			tok, err := c.Exchange(context.Background(), "code")
+			So(err, ShouldBeNil)
+			So(tok.AccessToken, ShouldEqual, "access1")
+			So(tok.RefreshToken, ShouldEqual, "refresh1")
+			So(tok.TokenType, ShouldEqual, "bearer")
+			So(tok.Scope, ShouldEqual, "read_stats,write_logged_time")
+			So(tok.UID, ShouldEqual, "uid")
+			So(tok.Expiry, ShouldHappenOnOrAfter, before.Add(time.Hour))
+			So(fs.requests[0].Get("redirect_uri"), ShouldEqual, "http://localhost/callback")
+		})
+		Convey("Code must be exchanged for form encoded token", func() {
+			fs.form = true
+			tok, err := c.Exchange(context.Background(), "code")
+			So(err, ShouldBeNil)
+			So(tok.AccessToken, ShouldEqual, "access1")
+			So(tok.RefreshToken, ShouldEqual, "refresh1")
+			So(tok.Scope, ShouldEqual, "read_stats")
+			So(tok.Expiry.IsZero(), ShouldBeFalse)
+		})
+		Convey("Invalid code must return APIError", func() {
+			_, err := c.Exchange(context.Background(), "bad")
+			var apiErr *APIError
+			So(errors.As(err, &apiErr), ShouldBeTrue)
+			So(apiErr.StatusCode, ShouldEqual, http.StatusBadRequest)
+		})
+		Convey("Invalid client must return unauthorized error", func() {
+			c.ClientSecret = "wrong"
+			_, err := c.Exchange(context.Background(), "code")
+			So(IsUnauthorized(err), ShouldBeTrue)
+		})
+	})
+}
+
+func TestOAuthTransport(t *testing.T) {
+	Convey("Given OAuth transport", t, func() {
+		fs := &FakeTokenServer{}
+		ts := httptest.NewServer(fs)
+		defer ts.Close()
+		c := newTestOAuthConfig(ts.URL)
+		tok, err := c.Exchange(context.Background(), "code")
+		So(err, ShouldBeNil)
+		dt := NewDummyTransport(users)
+		clock := NewFakeClock()
+		ot := NewOAuthTransport(c, tok)
+		ot.Transport = dt
+		ot.Clock = clock
+		var refreshed []*OAuthToken
+		ot.OnRefresh = func(t *OAuthToken) {
+			refreshed = append(refreshed, t)
+		}
+		wt := New(ot)
+		Convey("Valid token must be sent as bearer", func() {
+			tok.Expiry = clock.Now().Add(time.Hour)
+			_, err := wt.Users(CurrentUser)
+			So(err, ShouldBeNil)
+			So(dt.req.Header.Get("Authorization"), ShouldEqual, "Bearer access1")
+			So(dt.req.Header.Get("User-Agent"), ShouldEqual, getUserAgent())
+			So(refreshed, ShouldBeEmpty)
+		})
+		Convey("Expired token must be refreshed and rotated", func() {
+			tok.Expiry = clock.Now().Add(-time.Second)
+			_, err := wt.Users(CurrentUser)
+			So(err, ShouldBeNil)
+			So(dt.req.Header.Get("Authorization"), ShouldEqual, "Bearer access2")
+			So(refreshed, ShouldHaveLength, 1)
+			So(fs.requests[1].Get("grant_type"), ShouldEqual, "refresh_token")
+			So(fs.requests[1].Get("refresh_token"), ShouldEqual, "refresh1")
+			current := ot.Token()
+			So(current.RefreshToken, ShouldEqual, "refresh2")
+			So(current.Expiry, ShouldResemble, clock.Now().Add(time.Hour))
+			Convey("Next refresh must use the rotated refresh token", func() {
+				<-clock.After(time.Hour)
+				_, err := wt.Users(CurrentUser)
+				So(err, ShouldBeNil)
+				So(dt.req.Header.Get("Authorization"), ShouldEqual, "Bearer access3")
+				So(fs.requests[2].Get("refresh_token"), ShouldEqual, "refresh2")
+			})
+		})
+		Convey("Token about to expire must be refreshed", func() {
+			tok.Expiry = clock.Now().Add(30 * time.Second)
+			_, err := wt.Users(CurrentUser)
+			So(err, ShouldBeNil)
+			So(dt.req.Header.Get("Authorization"), ShouldEqual, "Bearer access2")
+		})
+		Convey("Expired token without refresh token must fail", func() {
+			tok.Expiry = clock.Now().Add(-time.Second)
+			tok.RefreshToken = ""
+			_, err := wt.Users(CurrentUser)
+			So(errors.Is(err, ErrNoRefreshToken), ShouldBeTrue)
+			So(dt.req, ShouldBeNil)
+		})
+	})
+	Convey("Given OAuth transport literal without clock", t, func() {
+		dt := NewDummyTransport(users)
+		ot := &OAuthTransport{Transport: dt, token: &OAuthToken{AccessToken: "access", Expiry: time.Now().Add(time.Hour)}}
+		Convey("The system clock must be used", func() {
+			_, err := New(ot).Users(CurrentUser)
+			So(err, ShouldBeNil)
+			So(dt.req.Header.Get("Authorization"), ShouldEqual, "Bearer access")
+		})
+	})
+	Convey("Given OAuth transport without token", t, func() {
+		dt := NewDummyTransport(users)
+		ot := NewOAuthTransport(NewOAuthConfig("id", "secret", ""), nil)
+		ot.Transport = dt
+		Convey("Request must fail", func() {
+			_, err := New(ot).Users(CurrentUser)
+			So(errors.Is(err, ErrNoOAuthToken), ShouldBeTrue)
+			So(dt.req, ShouldBeNil)
+			So(ot.Token(), ShouldResemble, OAuthToken{})
+		})
+	})
+}
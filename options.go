@@ -0,0 +1,59 @@
+package wakatime
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Option configures the WakaTime client
+type Option func(*WakaTime)
+
+// WithBaseURL sets the root URL of the API. Use it to target self-hosted
+// WakaTime compatible servers like Wakapi or Hakatime.
+func WithBaseURL(base string) Option {
+	return func(wt *WakaTime) {
+		if !strings.HasSuffix(base, "/") {
+			base += "/"
+		}
+		wt.baseURL = base
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for the requests. The client is
+// copied and the transport passed to New is used when the client has none.
+func WithHTTPClient(client *http.Client) Option {
+	return func(wt *WakaTime) {
+		c := *client
+		wt.client = &c
+	}
+}
+
+// WithUserAgentSuffix appends suffix to the User-Agent header of each request
+func WithUserAgentSuffix(suffix string) Option {
+	return func(wt *WakaTime) {
+		wt.userAgent = getUserAgent() + " " + suffix
+	}
+}
+
+// WithTimezone sets the default timezone for the reports which support it
+func WithTimezone(timezone string) Option {
+	return func(wt *WakaTime) {
+		wt.timezone = timezone
+	}
+}
+
+// WithTimeout sets the time limit for each request
+func WithTimeout(timeout time.Duration) Option {
+	return func(wt *WakaTime) {
+		wt.timeout = timeout
+	}
+}
+
+// WithRateLimiter throttles all requests made by the client using limiter. The
+// limiter can be shared between clients.
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(wt *WakaTime) {
+		wt.limiter = limiter
+	}
+}
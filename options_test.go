@@ -0,0 +1,79 @@
+package wakatime
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestOptions(t *testing.T) {
+	Convey("Given wakatime without options", t, func() {
+		dt := NewDummyTransport(users)
+		wt := New(dt)
+		Convey("Requests must use the default API base", func() {
+			_, err := wt.Users(CurrentUser)
+			So(err, ShouldBeNil)
+			So(dt.req.URL.String(), ShouldEqual, "https://wakatime.com/api/v1/users/current")
+			So(dt.req.Header.Get("User-Agent"), ShouldEqual, getUserAgent())
+		})
+	})
+	Convey("Given wakatime with custom base URL", t, func() {
+		dt := NewDummyTransport(durations)
+		wt := New(dt, WithBaseURL("http://localhost:3000/api/compat/wakatime/v1"))
+		Convey("Requests must use the configured base", func() {
+			_, err := wt.Durations(CurrentUser, time.Date(2015, 4, 23, 0, 0, 0, 0, time.UTC), nil, nil)
+			So(err, ShouldBeNil)
+			So(dt.req.URL.Scheme, ShouldEqual, "http")
+			So(dt.req.URL.Host, ShouldEqual, "localhost:3000")
+			So(dt.req.URL.Path, ShouldEqual, "/api/compat/wakatime/v1/users/current/durations")
+		})
+	})
+	Convey("Given path segments with dots", t, func() {
+		dt := NewDummyTransport(`{"data": {}}`)
+		wt := New(dt)
+		Convey("Dot segments must stay inside the endpoint", func() {
+			_, err := wt.Commits(context.Background(), CurrentUser, "..", "", 0)
+			So(err, ShouldBeNil)
+			So(dt.req.URL.EscapedPath(), ShouldEqual, "/api/v1/users/current/projects/%2E%2E/commits")
+			So(wt.DeleteProject(context.Background(), CurrentUser, "."), ShouldBeNil)
+			So(dt.req.URL.String(), ShouldEqual, "https://wakatime.com/api/v1/users/current/projects/%2E")
+		})
+		Convey("Dots inside names must not be escaped", func() {
+			_, err := wt.Commits(context.Background(), CurrentUser, "go-wakatime.v2", "", 0)
+			So(err, ShouldBeNil)
+			So(dt.req.URL.EscapedPath(), ShouldEqual, "/api/v1/users/current/projects/go-wakatime.v2/commits")
+		})
+	})
+	Convey("Given wakatime with user agent suffix and timezone", t, func() {
+		dt := NewDummyTransport(durations)
+		wt := New(dt, WithUserAgentSuffix("my-tool/1.0"), WithTimezone("Europe/Stockholm"))
+		Convey("Requests must carry the suffix and the timezone", func() {
+			_, err := wt.Durations(CurrentUser, time.Now(), nil, nil)
+			So(err, ShouldBeNil)
+			So(dt.req.Header.Get("User-Agent"), ShouldEqual, getUserAgent()+" my-tool/1.0")
+			So(dt.req.URL.Query().Get("timezone"), ShouldEqual, "Europe/Stockholm")
+		})
+		Convey("User agent must survive the basic transport", func() {
+			bt := NewBasicTransport("key")
+			bt.Transport = dt
+			wt := New(bt, WithUserAgentSuffix("my-tool/1.0"))
+			_, err := wt.Durations(CurrentUser, time.Now(), nil, nil)
+			So(err, ShouldBeNil)
+			So(dt.req.Header.Get("User-Agent"), ShouldEqual, getUserAgent()+" my-tool/1.0")
+		})
+	})
+	Convey("Given wakatime with custom HTTP client and timeout", t, func() {
+		dt := NewDummyTransport(users)
+		client := &http.Client{}
+		wt := New(dt, WithTimeout(5*time.Second), WithHTTPClient(client))
+		Convey("The client must be copied and configured", func() {
+			So(wt.client, ShouldNotEqual, client)
+			So(client.Transport, ShouldBeNil)
+			So(wt.client.Transport, ShouldEqual, dt)
+			So(wt.client.Timeout, ShouldEqual, 5*time.Second)
+		})
+	})
+}
@@ -0,0 +1,196 @@
+package wakatime
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// OrgData contains single organization
+type OrgData struct {
+	CanCreateDashboards   bool      `json:"can_create_dashboards"`
+	CanManageGroups       bool      `json:"can_manage_groups"`
+	CanViewAllMembers     bool      `json:"can_view_all_members"`
+	CreatedAt             time.Time `json:"created_at"`
+	CreatedByUserID       string    `json:"created_by_user_id"`
+	DefaultProjectPrivacy string    `json:"default_project_privacy"`
+	ID                    string
+	InvitedPeopleCount    int        `json:"invited_people_count"`
+	ModifiedAt            *time.Time `json:"modified_at"`
+	Name                  string
+	PeopleCount           int `json:"people_count"`
+	Timeout               int
+	Timezone              string
+	WritesOnly            bool `json:"writes_only"`
+}
+
+// Orgs contains the user's organizations
+type Orgs struct {
+	Data       []OrgData
+	Total      int
+	TotalPages int `json:"total_pages"`
+}
+
+// OrgDashboardData contains single organization dashboard
+type OrgDashboardData struct {
+	CanAddMembers             bool      `json:"can_add_members"`
+	CanRemoveMembers          bool      `json:"can_remove_members"`
+	CanViewDashboard          bool      `json:"can_view_dashboard"`
+	CreatedAt                 time.Time `json:"created_at"`
+	FullName                  string    `json:"full_name"`
+	HasChangedMembers         bool      `json:"has_changed_members"`
+	ID                        string
+	IsCurrentUserMember       bool       `json:"is_current_user_member"`
+	MembersCount              int        `json:"members_count"`
+	MembersCountHumanReadable string     `json:"members_count_human_readable"`
+	MembersWithTimezonesCount int        `json:"members_with_timezones_count"`
+	ModifiedAt                *time.Time `json:"modified_at"`
+	Name                      string
+	Timezone                  string
+}
+
+// OrgDashboards contains the organization's dashboards
+type OrgDashboards struct {
+	Data       []OrgDashboardData
+	Total      int
+	TotalPages int `json:"total_pages"`
+}
+
+// OrgDashboardMemberUser contains the public user data of a dashboard member
+type OrgDashboardMemberUser struct {
+	DisplayName string `json:"display_name"`
+	Email       string
+	FullName    string `json:"full_name"`
+	ID          string
+	Photo       string
+	Username    string
+}
+
+// OrgDashboardMemberData contains single dashboard member
+type OrgDashboardMemberData struct {
+	ID             string
+	IsOnlyViewOnly bool `json:"is_only_view_only"`
+	IsViewOnly     bool `json:"is_view_only"`
+	User           OrgDashboardMemberUser
+}
+
+// OrgDashboardMembers contains single page of the dashboard members
+type OrgDashboardMembers struct {
+	Data       []OrgDashboardMemberData
+	Total      int
+	TotalPages int `json:"total_pages"`
+}
+
+// Orgs fetches single page of the user's organizations. Page starts from 1.
+func (wt *WakaTime) Orgs(ctx context.Context, user string, page int) (*Orgs, error) {
+	var err error
+	var u *url.URL
+	if u, err = wt.apiURL("users", user, "orgs"); err != nil {
+		return nil, err
+	}
+	setPage(u, page)
+	var content []byte
+	if content, err = wt.fetchURL(ctx, u.String()); err != nil {
+		return nil, err
+	}
+	var o Orgs
+	if err = json.Unmarshal(content, &o); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// OrgDashboards fetches single page of the organization's dashboards. Page
+// starts from 1.
+func (wt *WakaTime) OrgDashboards(ctx context.Context, user, org string, page int) (*OrgDashboards, error) {
+	var err error
+	var u *url.URL
+	if u, err = wt.apiURL("users", user, "orgs", org, "dashboards"); err != nil {
+		return nil, err
+	}
+	setPage(u, page)
+	var content []byte
+	if content, err = wt.fetchURL(ctx, u.String()); err != nil {
+		return nil, err
+	}
+	var od OrgDashboards
+	if err = json.Unmarshal(content, &od); err != nil {
+		return nil, err
+	}
+	return &od, nil
+}
+
+// OrgDashboardMembers fetches single page of the dashboard members. Page
+// starts from 1.
+func (wt *WakaTime) OrgDashboardMembers(ctx context.Context, user, org, dashboard string, page int) (*OrgDashboardMembers, error) {
+	var err error
+	var u *url.URL
+	if u, err = wt.apiURL("users", user, "orgs", org, "dashboards", dashboard, "members"); err != nil {
+		return nil, err
+	}
+	setPage(u, page)
+	var content []byte
+	if content, err = wt.fetchURL(ctx, u.String()); err != nil {
+		return nil, err
+	}
+	var m OrgDashboardMembers
+	if err = json.Unmarshal(content, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// OrgDashboardMemberSummaries fetches the summaries report of the dashboard
+// member filtered by opts
+func (wt *WakaTime) OrgDashboardMemberSummaries(ctx context.Context, user, org, dashboard, member string, opts *SummariesOptions) (*Summaries, error) {
+	var err error
+	var u *url.URL
+	if u, err = wt.apiURL("users", user, "orgs", org, "dashboards", dashboard, "members", member, "summaries"); err != nil {
+		return nil, err
+	}
+	q := opts.Values()
+	wt.setTimezone(q)
+	u.RawQuery = q.Encode()
+	var content []byte
+	if content, err = wt.fetchURL(ctx, u.String()); err != nil {
+		return nil, err
+	}
+	var sm Summaries
+	if err = json.Unmarshal(content, &sm); err != nil {
+		return nil, err
+	}
+	return &sm, nil
+}
+
+// OrgDashboardMemberDurations fetches the durations report of the dashboard
+// member filtered by opts
+func (wt *WakaTime) OrgDashboardMemberDurations(ctx context.Context, user, org, dashboard, member string, opts *DurationsOptions) (*Durations, error) {
+	var err error
+	var u *url.URL
+	if u, err = wt.apiURL("users", user, "orgs", org, "dashboards", dashboard, "members", member, "durations"); err != nil {
+		return nil, err
+	}
+	q := opts.Values()
+	wt.setTimezone(q)
+	u.RawQuery = q.Encode()
+	var content []byte
+	if content, err = wt.fetchURL(ctx, u.String()); err != nil {
+		return nil, err
+	}
+	var dr Durations
+	if err = json.Unmarshal(content, &dr); err != nil {
+		return nil, err
+	}
+	return &dr, nil
+}
+
+// setPage adds the page to the query when it is set
+func setPage(u *url.URL, page int) {
+	if page > 0 {
+		q := u.Query()
+		q.Set("page", strconv.Itoa(page))
+		u.RawQuery = q.Encode()
+	}
+}
@@ -0,0 +1,134 @@
+package wakatime
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const (
+	orgs = `{
+  "data": [
+    {
+      "can_create_dashboards": true,
+      "can_manage_groups": false,
+      "can_view_all_members": true,
+      "created_at": "2015-04-23T04:38:05Z",
+      "created_by_user_id": "e9b45851-991b-4755-ffff-6355d927f472",
+      "default_project_privacy": "visible",
+      "id": "6a0b2f4e-8c1d-4e3f-9a5b-7c9d1e3f5a7b",
+      "invited_people_count": 2,
+      "modified_at": null,
+      "name": "Example Ltd",
+      "people_count": 14,
+      "timeout": 15,
+      "timezone": "Europe/Stockholm",
+      "writes_only": false
+    }
+  ],
+  "total": 1,
+  "total_pages": 1
+}`
+	orgDashboards = `{
+  "data": [
+    {
+      "can_add_members": true,
+      "can_remove_members": true,
+      "can_view_dashboard": true,
+      "created_at": "2015-04-23T04:40:00Z",
+      "full_name": "Example Ltd / Backend",
+      "has_changed_members": false,
+      "id": "3c5e7a9b-1d3f-4a5b-8c7d-9e1f3a5b7c9d",
+      "is_current_user_member": true,
+      "members_count": 5,
+      "members_count_human_readable": "5 members",
+      "members_with_timezones_count": 5,
+      "modified_at": null,
+      "name": "Backend",
+      "timezone": "Europe/Stockholm"
+    }
+  ],
+  "total": 1,
+  "total_pages": 1
+}`
+	orgDashboardMembers = `{
+  "data": [
+    {
+      "id": "e9b45851-991b-4755-ffff-6355d927f472",
+      "is_only_view_only": false,
+      "is_view_only": false,
+      "user": {
+        "display_name": "aquilax",
+        "email": "aquilax@example.com",
+        "full_name": "Full Name",
+        "id": "e9b45851-991b-4755-ffff-6355d927f472",
+        "photo": "https://wakatime.com/photo/e9b45851",
+        "username": "aquilax"
+      }
+    }
+  ],
+  "total": 1,
+  "total_pages": 1
+}`
+)
+
+func TestOrgs(t *testing.T) {
+	org := "6a0b2f4e-8c1d-4e3f-9a5b-7c9d1e3f5a7b"
+	dashboard := "3c5e7a9b-1d3f-4a5b-8c7d-9e1f3a5b7c9d"
+	member := "e9b45851-991b-4755-ffff-6355d927f472"
+	Convey("Given wakatime", t, func() {
+		Convey("Orgs JSON must be correctly parsed", func() {
+			dt := NewDummyTransport(orgs)
+			wt := New(dt)
+			o, err := wt.Orgs(context.Background(), CurrentUser, 0)
+			So(err, ShouldBeNil)
+			So(dt.req.URL.Path, ShouldEqual, "/api/v1/users/current/orgs")
+			So(dt.req.URL.RawQuery, ShouldBeEmpty)
+			So(len(o.Data), ShouldEqual, 1)
+			So(o.Data[0].Name, ShouldEqual, "Example Ltd")
+			So(o.Data[0].PeopleCount, ShouldEqual, 14)
+			So(o.Data[0].CreatedAt.Format(time.RFC3339), ShouldEqual, "2015-04-23T04:38:05Z")
+			So(o.Data[0].ModifiedAt, ShouldBeNil)
+		})
+		Convey("Org dashboards JSON must be correctly parsed", func() {
+			dt := NewDummyTransport(orgDashboards)
+			wt := New(dt)
+			d, err := wt.OrgDashboards(context.Background(), CurrentUser, org, 2)
+			So(err, ShouldBeNil)
+			So(dt.req.URL.Path, ShouldEqual, "/api/v1/users/current/orgs/"+org+"/dashboards")
+			So(dt.req.URL.RawQuery, ShouldEqual, "page=2")
+			So(d.Data[0].FullName, ShouldEqual, "Example Ltd / Backend")
+			So(d.Data[0].MembersCount, ShouldEqual, 5)
+			So(d.Data[0].IsCurrentUserMember, ShouldBeTrue)
+		})
+		Convey("Org dashboard members JSON must be correctly parsed", func() {
+			dt := NewDummyTransport(orgDashboardMembers)
+			wt := New(dt)
+			m, err := wt.OrgDashboardMembers(context.Background(), CurrentUser, org, dashboard, 3)
+			So(err, ShouldBeNil)
+			So(dt.req.URL.Path, ShouldEqual, "/api/v1/users/current/orgs/"+org+"/dashboards/"+dashboard+"/members")
+			So(dt.req.URL.RawQuery, ShouldEqual, "page=3")
+			So(m.Data[0].User.Username, ShouldEqual, "aquilax")
+		})
+		Convey("Member summaries must reuse the summaries report", func() {
+			dt := NewDummyTransport(summaries)
+			wt := New(dt, WithTimezone("Europe/Stockholm"))
+			s, err := wt.OrgDashboardMemberSummaries(context.Background(), CurrentUser, org, dashboard, member, &SummariesOptions{Range: RangeYesterday})
+			So(err, ShouldBeNil)
+			So(dt.req.URL.Path, ShouldEqual, "/api/v1/users/current/orgs/"+org+"/dashboards/"+dashboard+"/members/"+member+"/summaries")
+			So(dt.req.URL.RawQuery, ShouldEqual, "range=Yesterday&timezone=Europe%2FStockholm")
+			So(s.Data[0].GrandTotal.TotalSeconds, ShouldEqual, 11165)
+		})
+		Convey("Member durations must reuse the durations report", func() {
+			dt := NewDummyTransport(durations)
+			wt := New(dt)
+			d, err := wt.OrgDashboardMemberDurations(context.Background(), CurrentUser, org, dashboard, member, &DurationsOptions{Date: time.Date(2015, 4, 26, 0, 0, 0, 0, time.UTC)})
+			So(err, ShouldBeNil)
+			So(dt.req.URL.Path, ShouldEqual, "/api/v1/users/current/orgs/"+org+"/dashboards/"+dashboard+"/members/"+member+"/durations")
+			So(dt.req.URL.RawQuery, ShouldEqual, "date=04%2F26%2F2015")
+			So(d.Data[0].Project, ShouldEqual, "go-wakatime")
+		})
+	})
+}
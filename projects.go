@@ -0,0 +1,358 @@
+package wakatime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Repository contains the remote repository linked to a project
+type Repository struct {
+	CreatedAt     time.Time `json:"created_at"`
+	DefaultBranch string    `json:"default_branch"`
+	Description   string
+	ForkCount     int    `json:"fork_count"`
+	FullName      string `json:"full_name"`
+	Homepage      string
+	HTMLURL       string `json:"html_url"`
+	ID            string
+	ImageIconURL  string     `json:"image_icon_url"`
+	IsFork        bool       `json:"is_fork"`
+	IsPrivate     bool       `json:"is_private"`
+	LastSyncedAt  *time.Time `json:"last_synced_at"`
+	ModifiedAt    *time.Time `json:"modified_at"`
+	Name          string
+	Provider      string
+	StarCount     int `json:"star_count"`
+	URL           string
+	WatchCount    int `json:"watch_count"`
+}
+
+// ProjectData contains single project
+type ProjectData struct {
+	Color                        string
+	CreatedAt                    time.Time `json:"created_at"`
+	HasPublicURL                 bool      `json:"has_public_url"`
+	HumanReadableLastHeartbeatAt string    `json:"human_readable_last_heartbeat_at"`
+	ID                           string
+	LastHeartbeatAt              *time.Time `json:"last_heartbeat_at"`
+	Name                         string
+	Repository                   *Repository
+	URL                          string
+	URLEncodedName               string `json:"urlencoded_name"`
+}
+
+// Projects contains the user's projects
+type Projects struct {
+	Data []ProjectData
+}
+
+// CommitData contains single commit with the time spent on it
+type CommitData struct {
+	AuthorAvatarURL               string    `json:"author_avatar_url"`
+	AuthorDate                    time.Time `json:"author_date"`
+	AuthorEmail                   string    `json:"author_email"`
+	AuthorHTMLURL                 string    `json:"author_html_url"`
+	AuthorName                    string    `json:"author_name"`
+	AuthorURL                     string    `json:"author_url"`
+	AuthorUsername                string    `json:"author_username"`
+	Branch                        string
+	CommitterAvatarURL            string    `json:"committer_avatar_url"`
+	CommitterDate                 time.Time `json:"committer_date"`
+	CommitterEmail                string    `json:"committer_email"`
+	CommitterHTMLURL              string    `json:"committer_html_url"`
+	CommitterName                 string    `json:"committer_name"`
+	CommitterURL                  string    `json:"committer_url"`
+	CommitterUsername             string    `json:"committer_username"`
+	CreatedAt                     time.Time `json:"created_at"`
+	Hash                          string
+	HTMLURL                       string `json:"html_url"`
+	HumanReadableDate             string `json:"human_readable_date"`
+	HumanReadableNaturalDate      string `json:"human_readable_natural_date"`
+	HumanReadableTotal            string `json:"human_readable_total"`
+	HumanReadableTotalWithSeconds string `json:"human_readable_total_with_seconds"`
+	ID                            string
+	IsAuthorFound                 bool `json:"is_author_found"`
+	Message                       string
+	Ref                           string
+	TotalSeconds                  float64 `json:"total_seconds"`
+	TruncatedHash                 string  `json:"truncated_hash"`
+	URL                           string
+}
+
+// Commits contains single page of the project's commits
+type Commits struct {
+	Author     *string
+	Branch     string
+	Commits    []CommitData
+	NextPage   *int `json:"next_page"`
+	Page       int
+	PrevPage   *int `json:"prev_page"`
+	Project    ProjectData
+	Status     string
+	Total      int
+	TotalPages int `json:"total_pages"`
+}
+
+// Commit contains single commit report
+type Commit struct {
+	Branch  string
+	Commit  CommitData
+	Project ProjectData
+	Status  string
+}
+
+// Projects fetches the user's projects, optionally filtered by the query
+func (wt *WakaTime) Projects(ctx context.Context, user, query string) (*Projects, error) {
+	var err error
+	var u *url.URL
+	if u, err = wt.apiURL("users", user, "projects"); err != nil {
+		return nil, err
+	}
+	if query != "" {
+		q := u.Query()
+		q.Set("q", query)
+		u.RawQuery = q.Encode()
+	}
+	var content []byte
+	if content, err = wt.fetchURL(ctx, u.String()); err != nil {
+		return nil, err
+	}
+	var p Projects
+	if err = json.Unmarshal(content, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Commits fetches single page of the project's commits. Empty branch uses the
+// default branch and page starts from 1.
+func (wt *WakaTime) Commits(ctx context.Context, user, project, branch string, page int) (*Commits, error) {
+	var err error
+	var u *url.URL
+	if u, err = wt.apiURL("users", user, "projects", project, "commits"); err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	if branch != "" {
+		q.Set("branch", branch)
+	}
+	if page > 0 {
+		q.Set("page", strconv.Itoa(page))
+	}
+	u.RawQuery = q.Encode()
+	var content []byte
+	if content, err = wt.fetchURL(ctx, u.String()); err != nil {
+		return nil, err
+	}
+	var c Commits
+	if err = json.Unmarshal(content, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Commit fetches single project's commit by hash
+func (wt *WakaTime) Commit(ctx context.Context, user, project, hash string) (*Commit, error) {
+	var err error
+	var u *url.URL
+	if u, err = wt.apiURL("users", user, "projects", project, "commits", hash); err != nil {
+		return nil, err
+	}
+	var content []byte
+	if content, err = wt.fetchURL(ctx, u.String()); err != nil {
+		return nil, err
+	}
+	var c Commit
+	if err = json.Unmarshal(content, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// ProjectSettings contains the editable project settings. Empty fields are
+// left unchanged.
+type ProjectSettings struct {
+	Name         string `json:"name,omitempty"`
+	Color        string `json:"color,omitempty"`
+	HasPublicURL *bool  `json:"has_public_url,omitempty"`
+}
+
+// ProjectSettingsUpdate contains the change of single project's settings
+type ProjectSettingsUpdate struct {
+	Project string
+	Current ProjectSettings
+	Desired ProjectSettings
+}
+
+// ProjectSettingsPlan contains the changes needed to reach the desired project
+// settings. Projects are never deleted by the plan.
+type ProjectSettingsPlan struct {
+	Create []ProjectSettings
+	Update []ProjectSettingsUpdate
+}
+
+// projectResponse is the body of the response for a single project
+type projectResponse struct {
+	Data ProjectData
+}
+
+// Settings returns the editable settings of the project
+func (p *ProjectData) Settings() ProjectSettings {
+	hasPublicURL := p.HasPublicURL
+	return ProjectSettings{
+		Name:         p.Name,
+		Color:        p.Color,
+		HasPublicURL: &hasPublicURL,
+	}
+}
+
+// differs reports whether applying desired would change the settings
+func (s ProjectSettings) differs(desired ProjectSettings) bool {
+	return (desired.Name != "" && desired.Name != s.Name) ||
+		(desired.Color != "" && desired.Color != s.Color) ||
+		(desired.HasPublicURL != nil && (s.HasPublicURL == nil || *desired.HasPublicURL != *s.HasPublicURL))
+}
+
+// IsEmpty reports whether the plan has no changes
+func (p *ProjectSettingsPlan) IsEmpty() bool {
+	return len(p.Create) == 0 && len(p.Update) == 0
+}
+
+// String returns the plan as a diff, one change per line
+func (p *ProjectSettingsPlan) String() string {
+	var sb strings.Builder
+	for _, s := range p.Create {
+		fmt.Fprintf(&sb, "+ %s\n", s)
+	}
+	for _, u := range p.Update {
+		fmt.Fprintf(&sb, "~ %s\n  -> %s\n", u.Current, u.Desired)
+	}
+	return sb.String()
+}
+
+// String returns the settings in human readable form
+func (s ProjectSettings) String() string {
+	var parts []string
+	if s.Name != "" {
+		parts = append(parts, fmt.Sprintf("name=%q", s.Name))
+	}
+	if s.Color != "" {
+		parts = append(parts, fmt.Sprintf("color=%q", s.Color))
+	}
+	if s.HasPublicURL != nil {
+		parts = append(parts, fmt.Sprintf("has_public_url=%t", *s.HasPublicURL))
+	}
+	return strings.Join(parts, " ")
+}
+
+// PlanProjectSettings computes the changes needed to apply the desired
+// settings, keyed by the current project name, to the current projects
+func PlanProjectSettings(current []ProjectData, desired map[string]ProjectSettings) *ProjectSettingsPlan {
+	p := &ProjectSettingsPlan{}
+	names := make([]string, 0, len(desired))
+	for name := range desired {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		d := desired[name]
+		found := false
+		for i := range current {
+			if current[i].Name != name {
+				continue
+			}
+			found = true
+			if c := current[i].Settings(); c.differs(d) {
+				p.Update = append(p.Update, ProjectSettingsUpdate{Project: name, Current: c, Desired: d})
+			}
+			break
+		}
+		if !found {
+			if d.Name == "" {
+				d.Name = name
+			}
+			p.Create = append(p.Create, d)
+		}
+	}
+	return p
+}
+
+// CreateProject creates new project for the user
+func (wt *WakaTime) CreateProject(ctx context.Context, user string, settings ProjectSettings) (*ProjectData, error) {
+	var err error
+	var u *url.URL
+	if u, err = wt.apiURL("users", user, "projects"); err != nil {
+		return nil, err
+	}
+	return wt.sendProject(ctx, http.MethodPost, u, settings)
+}
+
+// UpdateProject changes the settings of the user's project
+func (wt *WakaTime) UpdateProject(ctx context.Context, user, project string, settings ProjectSettings) (*ProjectData, error) {
+	var err error
+	var u *url.URL
+	if u, err = wt.apiURL("users", user, "projects", project); err != nil {
+		return nil, err
+	}
+	return wt.sendProject(ctx, http.MethodPut, u, settings)
+}
+
+// DeleteProject deletes the user's project together with its logged time
+func (wt *WakaTime) DeleteProject(ctx context.Context, user, project string) error {
+	var err error
+	var u *url.URL
+	if u, err = wt.apiURL("users", user, "projects", project); err != nil {
+		return err
+	}
+	_, err = wt.sendURL(ctx, http.MethodDelete, u.String(), nil)
+	return err
+}
+
+// ApplyProjectSettings applies the desired settings, keyed by the current
+// project name, to the user's projects. It returns the planned changes and the
+// ones which were applied, which differ when a request fails. With dryRun the
+// plan is only computed against the current server state and nothing is
+// changed.
+func (wt *WakaTime) ApplyProjectSettings(ctx context.Context, user string, desired map[string]ProjectSettings, dryRun bool) (planned, applied *ProjectSettingsPlan, err error) {
+	current, err := wt.Projects(ctx, user, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	planned = PlanProjectSettings(current.Data, desired)
+	applied = &ProjectSettingsPlan{}
+	if dryRun {
+		return planned, applied, nil
+	}
+	for _, up := range planned.Update {
+		if _, err = wt.UpdateProject(ctx, user, up.Project, up.Desired); err != nil {
+			return planned, applied, err
+		}
+		applied.Update = append(applied.Update, up)
+	}
+	for _, s := range planned.Create {
+		if _, err = wt.CreateProject(ctx, user, s); err != nil {
+			return planned, applied, err
+		}
+		applied.Create = append(applied.Create, s)
+	}
+	return planned, applied, nil
+}
+
+func (wt *WakaTime) sendProject(ctx context.Context, method string, u *url.URL, settings ProjectSettings) (*ProjectData, error) {
+	content, err := wt.sendURL(ctx, method, u.String(), settings)
+	if err != nil {
+		return nil, err
+	}
+	var pr projectResponse
+	if err = json.Unmarshal(content, &pr); err != nil {
+		return nil, err
+	}
+	return &pr.Data, nil
+}
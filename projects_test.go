@@ -0,0 +1,173 @@
+package wakatime
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const (
+	projects = `{
+  "data": [
+    {
+      "color": null,
+      "created_at": "2015-04-23T04:38:05Z",
+      "has_public_url": false,
+      "human_readable_last_heartbeat_at": "2 days ago",
+      "id": "198e4ed6-a208-41b7-b698-1826a003411a",
+      "last_heartbeat_at": "2015-04-26T04:16:23Z",
+      "name": "go-wakatime",
+      "repository": {
+        "created_at": "2015-04-23T04:40:00Z",
+        "default_branch": "master",
+        "description": "Go library for accessing WakaTime API",
+        "fork_count": 3,
+        "full_name": "aquilax/go-wakatime",
+        "homepage": null,
+        "html_url": "https://github.com/aquilax/go-wakatime",
+        "id": "c2b4b3f0-1a2b-4c3d-8e9f-0a1b2c3d4e5f",
+        "is_fork": false,
+        "is_private": false,
+        "last_synced_at": null,
+        "modified_at": null,
+        "name": "go-wakatime",
+        "provider": "github",
+        "star_count": 12,
+        "url": "https://api.github.com/repos/aquilax/go-wakatime",
+        "watch_count": 2
+      },
+      "url": "/projects/go-wakatime",
+      "urlencoded_name": "go-wakatime"
+    }
+  ]
+}`
+	commits = `{
+  "author": null,
+  "branch": "master",
+  "commits": [
+    {
+      "author_avatar_url": "https://avatars.githubusercontent.com/u/1",
+      "author_date": "2015-04-25T10:00:00Z",
+      "author_email": "aquilax@example.com",
+      "author_name": "aquilax",
+      "author_username": "aquilax",
+      "branch": "master",
+      "committer_date": "2015-04-25T10:00:00Z",
+      "committer_name": "aquilax",
+      "created_at": "2015-04-25T10:05:00Z",
+      "hash": "e0de41534b1f0a4c5d6e7f8091a2b3c4d5e6f708",
+      "html_url": "https://github.com/aquilax/go-wakatime/commit/e0de41534b1f0a4c5d6e7f8091a2b3c4d5e6f708",
+      "human_readable_date": "Apr 25th 2015",
+      "human_readable_natural_date": "3 days ago",
+      "human_readable_total": "1 hr 2 mins",
+      "human_readable_total_with_seconds": "1 hr 2 mins 5 secs",
+      "id": "8f2a6e34-5b7c-4d9e-a1f2-3b4c5d6e7f80",
+      "is_author_found": true,
+      "message": "Add durations report",
+      "ref": "refs/heads/master",
+      "total_seconds": 3725.5,
+      "truncated_hash": "e0de415",
+      "url": "https://api.github.com/repos/aquilax/go-wakatime/commits/e0de415"
+    }
+  ],
+  "next_page": 2,
+  "page": 1,
+  "prev_page": null,
+  "project": {"id": "198e4ed6-a208-41b7-b698-1826a003411a", "name": "go-wakatime"},
+  "status": "ok",
+  "total": 31,
+  "total_pages": 2
+}`
+)
+
+func TestProjects(t *testing.T) {
+	Convey("Given wakatime", t, func() {
+		Convey("Projects JSON must be correctly parsed", func() {
+			dt := NewDummyTransport(projects)
+			wt := New(dt)
+			p, err := wt.Projects(context.Background(), CurrentUser, "waka")
+			So(err, ShouldBeNil)
+			So(dt.req.URL.Path, ShouldEqual, "/api/v1/users/current/projects")
+			So(dt.req.URL.RawQuery, ShouldEqual, "q=waka")
+			So(len(p.Data), ShouldEqual, 1)
+			So(p.Data[0].Name, ShouldEqual, "go-wakatime")
+			So(p.Data[0].LastHeartbeatAt.Format(time.RFC3339), ShouldEqual, "2015-04-26T04:16:23Z")
+			So(p.Data[0].Repository.FullName, ShouldEqual, "aquilax/go-wakatime")
+			So(p.Data[0].Repository.DefaultBranch, ShouldEqual, "master")
+			So(p.Data[0].Repository.StarCount, ShouldEqual, 12)
+			So(p.Data[0].Repository.LastSyncedAt, ShouldBeNil)
+		})
+		Convey("Commits JSON must be correctly parsed", func() {
+			dt := NewDummyTransport(commits)
+			wt := New(dt)
+			c, err := wt.Commits(context.Background(), CurrentUser, "go-wakatime", "master", 1)
+			So(err, ShouldBeNil)
+			So(dt.req.URL.Path, ShouldEqual, "/api/v1/users/current/projects/go-wakatime/commits")
+			So(dt.req.URL.RawQuery, ShouldEqual, "branch=master&page=1")
+			So(c.Branch, ShouldEqual, "master")
+			So(c.Author, ShouldBeNil)
+			So(*c.NextPage, ShouldEqual, 2)
+			So(c.PrevPage, ShouldBeNil)
+			So(c.TotalPages, ShouldEqual, 2)
+			So(c.Project.Name, ShouldEqual, "go-wakatime")
+			So(len(c.Commits), ShouldEqual, 1)
+			So(c.Commits[0].TruncatedHash, ShouldEqual, "e0de415")
+			So(c.Commits[0].TotalSeconds, ShouldEqual, 3725.5)
+			So(c.Commits[0].HumanReadableTotalWithSeconds, ShouldEqual, "1 hr 2 mins 5 secs")
+			So(c.Commits[0].AuthorDate.Format(time.RFC3339), ShouldEqual, "2015-04-25T10:00:00Z")
+		})
+		Convey("Project names must be escaped", func() {
+			dt := NewDummyTransport(`{"branch": "master", "commit": {"hash": "e0de415"}, "status": "ok"}`)
+			wt := New(dt)
+			c, err := wt.Commit(context.Background(), CurrentUser, "team/app 2", "e0de415")
+			So(err, ShouldBeNil)
+			So(c.Commit.Hash, ShouldEqual, "e0de415")
+			So(dt.req.URL.EscapedPath(), ShouldEqual, "/api/v1/users/current/projects/team%2Fapp%202/commits/e0de415")
+		})
+	})
+}
+
+func TestProjectSettings(t *testing.T) {
+	public := true
+	Convey("Given wakatime", t, func() {
+		rt := &RoutesTransport{routes: map[string]string{"GET /api/v1/users/current/projects": projects}}
+		wt := New(rt)
+		desired := map[string]ProjectSettings{
+			"go-wakatime": {Color: "#00ADD8", HasPublicURL: &public},
+			"new-project": {Color: "#FF0000"},
+		}
+		Convey("Dry run must only plan the changes", func() {
+			p, applied, err := wt.ApplyProjectSettings(context.Background(), CurrentUser, desired, true)
+			So(err, ShouldBeNil)
+			So(applied.IsEmpty(), ShouldBeTrue)
+			So(rt.calls, ShouldResemble, []string{"GET /api/v1/users/current/projects"})
+			So(p.Create, ShouldResemble, []ProjectSettings{{Name: "new-project", Color: "#FF0000"}})
+			So(len(p.Update), ShouldEqual, 1)
+			So(p.Update[0].Project, ShouldEqual, "go-wakatime")
+			So(p.String(), ShouldEqual, `+ name="new-project" color="#FF0000"
+~ name="go-wakatime" has_public_url=false
+  -> color="#00ADD8" has_public_url=true
+`)
+		})
+		Convey("Apply must send the planned changes", func() {
+			p, applied, err := wt.ApplyProjectSettings(context.Background(), CurrentUser, desired, false)
+			So(err, ShouldBeNil)
+			So(applied.String(), ShouldEqual, p.String())
+			So(rt.calls, ShouldResemble, []string{
+				"GET /api/v1/users/current/projects",
+				"PUT /api/v1/users/current/projects/go-wakatime",
+				"POST /api/v1/users/current/projects",
+			})
+		})
+		Convey("Unchanged settings must not be planned", func() {
+			p := PlanProjectSettings([]ProjectData{{Name: "go-wakatime", Color: "#00ADD8"}}, map[string]ProjectSettings{"go-wakatime": {Color: "#00ADD8"}})
+			So(p.IsEmpty(), ShouldBeTrue)
+		})
+		Convey("Project must be deleted", func() {
+			So(wt.DeleteProject(context.Background(), CurrentUser, "old project"), ShouldBeNil)
+			So(rt.calls, ShouldResemble, []string{"DELETE /api/v1/users/current/projects/old project"})
+		})
+	})
+}
@@ -0,0 +1,112 @@
+package wakatime
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// ErrRateLimitWait is returned by RateLimiter.Wait when the required wait
+// exceeds the context deadline. It wraps context.DeadlineExceeded.
+var ErrRateLimitWait = fmt.Errorf("rate limit wait exceeds context deadline: %w", context.DeadlineExceeded)
+
+// RateLimiter is a token bucket limiter which can be shared by concurrent
+// callers. Create it with NewRateLimiter, the zero value allows no requests.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  int
+	tokens float64
+	last   time.Time
+	// Clock is used for time measurement and waiting, the system clock is
+	// used when nil
+	Clock Clock
+}
+
+// NewRateLimiter creates new RateLimiter allowing rate requests per second
+// with bursts of up to burst requests. It panics if rate is not positive.
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if !(rate > 0) || math.IsInf(rate, 1) {
+		panic(fmt.Sprintf("wakatime: invalid rate limiter rate %v", rate))
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:   rate,
+		burst:  burst,
+		tokens: float64(burst),
+		Clock:  systemClock{},
+	}
+}
+
+// advance refills the bucket up to now. Must be called with the lock held.
+func (l *RateLimiter) advance(now time.Time) {
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > float64(l.burst) {
+			l.tokens = float64(l.burst)
+		}
+	}
+	l.last = now
+}
+
+// delay returns the wait until a token is available. Must be called with the
+// lock held.
+func (l *RateLimiter) delay() time.Duration {
+	if l.tokens >= 1 {
+		return 0
+	}
+	if l.rate <= 0 {
+		// the bucket is never refilled
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
+
+func (l *RateLimiter) clock() Clock {
+	if l.Clock == nil {
+		return systemClock{}
+	}
+	return l.Clock
+}
+
+// Delay returns how long a request made now would have to wait
+func (l *RateLimiter) Delay() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.advance(l.clock().Now())
+	return l.delay()
+}
+
+// Wait blocks until a request is allowed or the context is done. When the
+// wait would outlast the context deadline, Wait returns ErrRateLimitWait
+// immediately so the caller can give up early.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	clock := l.clock()
+	now := clock.Now()
+	l.advance(now)
+	wait := l.delay()
+	if deadline, ok := ctx.Deadline(); ok && now.Add(wait).After(deadline) {
+		l.mu.Unlock()
+		return ErrRateLimitWait
+	}
+	l.tokens--
+	l.mu.Unlock()
+	if wait == 0 {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		// give the reserved token back
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	case <-clock.After(wait):
+		return nil
+	}
+}
@@ -0,0 +1,89 @@
+package wakatime
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRateLimiter(t *testing.T) {
+	Convey("Given rate limiter with fake clock", t, func() {
+		clock := NewFakeClock()
+		l := NewRateLimiter(10, 2)
+		l.Clock = clock
+		Convey("Burst must pass and the rest must be throttled", func() {
+			for i := 0; i < 5; i++ {
+				So(l.Wait(context.Background()), ShouldBeNil)
+			}
+			So(clock.waits, ShouldResemble, []time.Duration{100 * time.Millisecond, 100 * time.Millisecond, 100 * time.Millisecond})
+		})
+		Convey("Delay must expose the wait time", func() {
+			So(l.Delay(), ShouldEqual, 0)
+			So(l.Wait(context.Background()), ShouldBeNil)
+			So(l.Wait(context.Background()), ShouldBeNil)
+			So(l.Delay(), ShouldEqual, 100*time.Millisecond)
+		})
+		Convey("Wait must give up when the deadline is too close", func() {
+			So(l.Wait(context.Background()), ShouldBeNil)
+			So(l.Wait(context.Background()), ShouldBeNil)
+			ctx, cancel := context.WithDeadline(context.Background(), clock.Now().Add(50*time.Millisecond))
+			defer cancel()
+			err := l.Wait(ctx)
+			So(err, ShouldEqual, ErrRateLimitWait)
+			So(errors.Is(err, context.DeadlineExceeded), ShouldBeTrue)
+			So(clock.waits, ShouldBeEmpty)
+			So(l.Delay(), ShouldEqual, 100*time.Millisecond)
+		})
+		Convey("Client must wait for the limiter before each request", func() {
+			dt := NewDummyTransport(users)
+			wt := New(dt, WithRateLimiter(l))
+			for i := 0; i < 3; i++ {
+				_, err := wt.Users(CurrentUser)
+				So(err, ShouldBeNil)
+			}
+			So(clock.waits, ShouldResemble, []time.Duration{100 * time.Millisecond})
+		})
+	})
+	Convey("Given rate limiter shared by concurrent callers", t, func() {
+		l := NewRateLimiter(200, 1)
+		Convey("All callers must be throttled together", func() {
+			start := time.Now()
+			var wg sync.WaitGroup
+			for i := 0; i < 5; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					l.Wait(context.Background())
+				}()
+			}
+			wg.Wait()
+			So(time.Since(start), ShouldBeGreaterThanOrEqualTo, 20*time.Millisecond)
+		})
+	})
+	Convey("Given invalid rate", t, func() {
+		Convey("Rate limiter must not be created", func() {
+			So(func() { NewRateLimiter(0, 1) }, ShouldPanic)
+			So(func() { NewRateLimiter(-1, 1) }, ShouldPanic)
+		})
+	})
+	Convey("Given rate limiter without clock", t, func() {
+		l := NewRateLimiter(1000, 1)
+		l.Clock = nil
+		Convey("The system clock must be used", func() {
+			So(l.Wait(context.Background()), ShouldBeNil)
+			So(l.Wait(context.Background()), ShouldBeNil)
+		})
+	})
+	Convey("Given zero rate limiter", t, func() {
+		l := &RateLimiter{}
+		Convey("No request must be allowed", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+			defer cancel()
+			So(l.Wait(ctx), ShouldEqual, ErrRateLimitWait)
+		})
+	})
+}
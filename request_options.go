@@ -0,0 +1,160 @@
+package wakatime
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// SummariesRange is a named date range accepted by the summaries report
+type SummariesRange string
+
+// Summaries report named ranges
+const (
+	RangeToday                  SummariesRange = "Today"
+	RangeYesterday              SummariesRange = "Yesterday"
+	RangeLast7Days              SummariesRange = "Last 7 Days"
+	RangeLast7DaysFromYesterday SummariesRange = "Last 7 Days from Yesterday"
+	RangeLast14Days             SummariesRange = "Last 14 Days"
+	RangeLast30Days             SummariesRange = "Last 30 Days"
+	RangeThisWeek               SummariesRange = "This Week"
+	RangeLastWeek               SummariesRange = "Last Week"
+	RangeThisMonth              SummariesRange = "This Month"
+	RangeLastMonth              SummariesRange = "Last Month"
+)
+
+// SummariesOptions contains the filters for the summaries report. Either Range
+// or Start and End must be set.
+type SummariesOptions struct {
+	// Start is the first day of the report
+	Start time.Time
+	// End is the last day of the report
+	End time.Time
+	// Range is used instead of Start and End when set
+	Range SummariesRange
+	// Project limits the report to a single project
+	Project string
+	// Branches limits the report to comma separated branch names
+	Branches string
+	// Timezone overrides the client's default timezone
+	Timezone string
+	// WritesOnly limits the report to heartbeats with file writes
+	WritesOnly *bool
+}
+
+// Values encodes the options as query parameters
+func (o *SummariesOptions) Values() url.Values {
+	q := url.Values{}
+	if o == nil {
+		return q
+	}
+	if o.Range != "" {
+		q.Set("range", o.Range.String())
+	} else {
+		q.Set("start", o.Start.Format(dateFormat))
+		q.Set("end", o.End.Format(dateFormat))
+	}
+	if o.Project != "" {
+		q.Set("project", o.Project)
+	}
+	if o.Branches != "" {
+		q.Set("branches", o.Branches)
+	}
+	if o.Timezone != "" {
+		q.Set("timezone", o.Timezone)
+	}
+	if o.WritesOnly != nil {
+		q.Set("writes_only", strconv.FormatBool(*o.WritesOnly))
+	}
+	return q
+}
+
+// String returns the string representation of SummariesRange
+func (r SummariesRange) String() string {
+	return string(r)
+}
+
+// StatsOptions contains the filters for the stats report
+type StatsOptions struct {
+	// Timeout is the keystroke timeout in minutes, nil uses the user's setting
+	Timeout *int
+	// WritesOnly limits the report to heartbeats with file writes
+	WritesOnly *bool
+	// Project limits the report to a single project
+	Project string
+}
+
+// Values encodes the options as query parameters
+func (o *StatsOptions) Values() url.Values {
+	q := url.Values{}
+	if o == nil {
+		return q
+	}
+	if o.Timeout != nil {
+		q.Set("timeout", strconv.Itoa(*o.Timeout))
+	}
+	if o.WritesOnly != nil {
+		q.Set("writes_only", strconv.FormatBool(*o.WritesOnly))
+	}
+	if o.Project != "" {
+		q.Set("project", o.Project)
+	}
+	return q
+}
+
+// DurationsOptions contains the filters for the durations report
+type DurationsOptions struct {
+	// Date is the day of the report
+	Date time.Time
+	// Project limits the report to a single project
+	Project string
+	// Branches limits the report to comma separated branch names
+	Branches string
+	// Timezone overrides the client's default timezone
+	Timezone string
+	// SliceBy splits the durations by the given attribute, e.g. language
+	SliceBy string
+}
+
+// Values encodes the options as query parameters
+func (o *DurationsOptions) Values() url.Values {
+	q := url.Values{}
+	if o == nil {
+		return q
+	}
+	q.Set("date", o.Date.Format(dateFormat))
+	if o.Project != "" {
+		q.Set("project", o.Project)
+	}
+	if o.Branches != "" {
+		q.Set("branches", o.Branches)
+	}
+	if o.Timezone != "" {
+		q.Set("timezone", o.Timezone)
+	}
+	if o.SliceBy != "" {
+		q.Set("slice_by", o.SliceBy)
+	}
+	return q
+}
+
+// HeartbeatsOptions contains the filters for the heartbeats request
+type HeartbeatsOptions struct {
+	// Date is the day of the heartbeats
+	Date time.Time
+	// Timezone overrides the client's default timezone
+	Timezone string
+}
+
+// Values encodes the options as query parameters
+func (o *HeartbeatsOptions) Values() url.Values {
+	q := url.Values{}
+	if o == nil {
+		return q
+	}
+	q.Set("date", o.Date.Format(dateFormat))
+	if o.Timezone != "" {
+		q.Set("timezone", o.Timezone)
+	}
+	return q
+}
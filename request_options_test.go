@@ -0,0 +1,89 @@
+package wakatime
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRequestOptions(t *testing.T) {
+	date := time.Date(2015, 4, 23, 0, 0, 0, 0, time.UTC)
+	writesOnly := false
+	Convey("Given nil options", t, func() {
+		Convey("No parameters must be encoded", func() {
+			var so *StatsOptions
+			So(so.Values(), ShouldBeEmpty)
+			var do *DurationsOptions
+			So(do.Values(), ShouldBeEmpty)
+			var smo *SummariesOptions
+			So(smo.Values(), ShouldBeEmpty)
+			var ho *HeartbeatsOptions
+			So(ho.Values(), ShouldBeEmpty)
+		})
+	})
+	Convey("Given stats options", t, func() {
+		timeout := 15
+		opts := &StatsOptions{Timeout: &timeout, WritesOnly: &writesOnly, Project: "go-wakatime"}
+		So(opts.Values().Encode(), ShouldEqual, "project=go-wakatime&timeout=15&writes_only=false")
+	})
+	Convey("Given durations options", t, func() {
+		opts := &DurationsOptions{Date: date, Project: "go-wakatime", Branches: "master", Timezone: "UTC", SliceBy: "language"}
+		So(opts.Values().Encode(), ShouldEqual, "branches=master&date=04%2F23%2F2015&project=go-wakatime&slice_by=language&timezone=UTC")
+	})
+	Convey("Given heartbeats options", t, func() {
+		opts := &HeartbeatsOptions{Date: date, Timezone: "UTC"}
+		So(opts.Values().Encode(), ShouldEqual, "date=04%2F23%2F2015&timezone=UTC")
+	})
+	Convey("Given wakatime", t, func() {
+		Convey("Stats must use the options", func() {
+			dt := NewDummyTransport(stats)
+			wt := New(dt)
+			_, err := wt.StatsWithOptions(context.Background(), CurrentUser, Last30Days, &StatsOptions{Project: "go-wakatime"})
+			So(err, ShouldBeNil)
+			So(dt.req.URL.Path, ShouldEqual, "/api/v1/users/current/stats/last_30_days")
+			So(dt.req.URL.RawQuery, ShouldEqual, "project=go-wakatime")
+		})
+		Convey("Deprecated stats must map to the options", func() {
+			dt := NewDummyTransport(stats)
+			wt := New(dt)
+			timeout := 10
+			_, err := wt.Stats(CurrentUser, Last7Days, &timeout, &writesOnly, nil)
+			So(err, ShouldBeNil)
+			So(dt.req.URL.RawQuery, ShouldEqual, "timeout=10&writes_only=false")
+		})
+		Convey("Deprecated stats must keep explicit zero timeout", func() {
+			dt := NewDummyTransport(stats)
+			wt := New(dt)
+			zero := 0
+			_, err := wt.Stats(CurrentUser, Last7Days, &zero, nil, nil)
+			So(err, ShouldBeNil)
+			So(dt.req.URL.RawQuery, ShouldEqual, "timeout=0")
+		})
+		Convey("Durations must use the options", func() {
+			dt := NewDummyTransport(durations)
+			wt := New(dt)
+			_, err := wt.DurationsWithOptions(context.Background(), CurrentUser, &DurationsOptions{Date: date, SliceBy: "entity"})
+			So(err, ShouldBeNil)
+			So(dt.req.URL.Path, ShouldEqual, "/api/v1/users/current/durations")
+			So(dt.req.URL.RawQuery, ShouldEqual, "date=04%2F23%2F2015&slice_by=entity")
+		})
+		Convey("Deprecated durations must map to the options", func() {
+			dt := NewDummyTransport(durations)
+			wt := New(dt)
+			branches := "master"
+			_, err := wt.Durations(CurrentUser, date, nil, &branches)
+			So(err, ShouldBeNil)
+			So(dt.req.URL.RawQuery, ShouldEqual, "branches=master&date=04%2F23%2F2015")
+		})
+		Convey("Heartbeats must use the options", func() {
+			dt := NewDummyTransport(hartbeat)
+			wt := New(dt)
+			_, err := wt.HeartbeatsWithOptions(context.Background(), CurrentUser, &HeartbeatsOptions{Date: date})
+			So(err, ShouldBeNil)
+			So(dt.req.URL.Path, ShouldEqual, "/api/v1/users/current/heartbeats")
+			So(dt.req.URL.RawQuery, ShouldEqual, "date=04%2F23%2F2015")
+		})
+	})
+}
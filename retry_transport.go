@@ -0,0 +1,158 @@
+package wakatime
+
+import (
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// Clock provides the current time and timers. It allows time dependent code to
+// be tested without waiting.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// Default retry settings used by NewRetryTransport
+const (
+	DefaultMaxAttempts = 4
+	DefaultMaxElapsed  = 2 * time.Minute
+	DefaultBaseDelay   = 500 * time.Millisecond
+	DefaultMaxDelay    = 30 * time.Second
+)
+
+// RetryTransport implements http.RoundTripper and retries idempotent requests
+// failing with network errors, 5xx or 429 responses using jittered exponential
+// backoff
+type RetryTransport struct {
+	Transport http.RoundTripper
+	// MaxAttempts is the maximum number of attempts including the first one
+	MaxAttempts int
+	// MaxElapsed caps the total time spent on retrying, zero means no limit
+	MaxElapsed time.Duration
+	// BaseDelay is the delay before the first retry
+	BaseDelay time.Duration
+	// MaxDelay caps the computed backoff delay
+	MaxDelay time.Duration
+	// Clock is used for time measurement and waiting, the system clock is
+	// used when nil
+	Clock Clock
+	// Jitter returns a random number in [0, 1) used to spread the delays,
+	// rand.Float64 is used when nil
+	Jitter func() float64
+}
+
+// NewRetryTransport creates new RetryTransport wrapping rt with the default
+// settings
+func NewRetryTransport(rt http.RoundTripper) *RetryTransport {
+	return &RetryTransport{
+		Transport:   rt,
+		MaxAttempts: DefaultMaxAttempts,
+		MaxElapsed:  DefaultMaxElapsed,
+		BaseDelay:   DefaultBaseDelay,
+		MaxDelay:    DefaultMaxDelay,
+		Clock:       systemClock{},
+		Jitter:      rand.Float64,
+	}
+}
+
+// RoundTrip implements the http.RoundTripper method
+func (rt *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isRetryable(req) {
+		return rt.Transport.RoundTrip(req)
+	}
+	ctx := req.Context()
+	clock := rt.clock()
+	start := clock.Now()
+	for attempt := 1; ; attempt++ {
+		r := req
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r = cloneRequest(req)
+			r.Body = body
+		}
+		resp, err := rt.Transport.RoundTrip(r)
+		if !shouldRetry(resp, err) || attempt >= rt.MaxAttempts || ctx.Err() != nil {
+			return resp, err
+		}
+		delay := rt.backoff(attempt)
+		if resp != nil {
+			if ra := parseRetryAfter(resp.Header, clock.Now()); ra > 0 {
+				delay = ra
+			}
+		}
+		if rt.MaxElapsed > 0 && clock.Now().Add(delay).Sub(start) > rt.MaxElapsed {
+			return resp, err
+		}
+		if resp != nil {
+			// drain the body so the connection can be reused
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-clock.After(delay):
+		}
+	}
+}
+
+func (rt *RetryTransport) clock() Clock {
+	if rt.Clock == nil {
+		return systemClock{}
+	}
+	return rt.Clock
+}
+
+func (rt *RetryTransport) jitter() float64 {
+	if rt.Jitter == nil {
+		return rand.Float64()
+	}
+	return rt.Jitter()
+}
+
+// backoff returns the delay before the next attempt
+func (rt *RetryTransport) backoff(attempt int) time.Duration {
+	delay := rt.BaseDelay
+	for i := 1; i < attempt && delay < rt.MaxDelay; i++ {
+		delay *= 2
+	}
+	if rt.MaxDelay > 0 && delay > rt.MaxDelay {
+		delay = rt.MaxDelay
+	}
+	// equal jitter: keep half of the delay and randomize the rest
+	half := delay / 2
+	return half + time.Duration(rt.jitter()*float64(delay-half))
+}
+
+// isRetryable reports whether the request is idempotent and can be resent
+func isRetryable(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+	default:
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+}
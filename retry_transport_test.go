@@ -0,0 +1,164 @@
+package wakatime
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// FakeClock advances its time only when waited on
+type FakeClock struct {
+	mu    sync.Mutex
+	now   time.Time
+	waits []time.Duration
+}
+
+func NewFakeClock() *FakeClock {
+	return &FakeClock{now: time.Date(2015, 4, 23, 0, 0, 0, 0, time.UTC)}
+}
+
+func (fc *FakeClock) Now() time.Time {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return fc.now
+}
+
+func (fc *FakeClock) After(d time.Duration) <-chan time.Time {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	fc.waits = append(fc.waits, d)
+	fc.now = fc.now.Add(d)
+	c := make(chan time.Time, 1)
+	c <- fc.now
+	return c
+}
+
+// SequenceTransport responds with the given status codes in order, negative
+// codes produce a network error
+type SequenceTransport struct {
+	codes  []int
+	header http.Header
+	bodies []string
+	calls  int
+}
+
+func (st *SequenceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	code := st.codes[st.calls]
+	st.calls++
+	if req.Body != nil {
+		b, _ := ioutil.ReadAll(req.Body)
+		st.bodies = append(st.bodies, string(b))
+	}
+	if code < 0 {
+		return nil, errors.New("connection reset")
+	}
+	return &http.Response{
+		StatusCode: code,
+		Header:     st.header,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(users)),
+	}, nil
+}
+
+func newTestRetryTransport(st *SequenceTransport, clock *FakeClock) *RetryTransport {
+	rt := NewRetryTransport(st)
+	rt.Clock = clock
+	rt.Jitter = func() float64 { return 0 }
+	return rt
+}
+
+func TestRetryTransport(t *testing.T) {
+	Convey("Given retry transport", t, func() {
+		clock := NewFakeClock()
+		Convey("Transient failures must be retried with exponential backoff", func() {
+			st := &SequenceTransport{codes: []int{-1, 500, 503, 200}, header: http.Header{}}
+			wt := New(newTestRetryTransport(st, clock))
+			u, err := wt.Users(CurrentUser)
+			So(err, ShouldBeNil)
+			So(u.Data.Username, ShouldEqual, "aquilax")
+			So(st.calls, ShouldEqual, 4)
+			So(clock.waits, ShouldResemble, []time.Duration{250 * time.Millisecond, 500 * time.Millisecond, time.Second})
+		})
+		Convey("Attempts must be capped", func() {
+			st := &SequenceTransport{codes: []int{502, 502, 502, 502, 502}, header: http.Header{}}
+			wt := New(newTestRetryTransport(st, clock))
+			_, err := wt.Users(CurrentUser)
+			So(err.(*APIError).StatusCode, ShouldEqual, 502)
+			So(st.calls, ShouldEqual, DefaultMaxAttempts)
+		})
+		Convey("Client errors must not be retried", func() {
+			st := &SequenceTransport{codes: []int{404, 200}, header: http.Header{}}
+			wt := New(newTestRetryTransport(st, clock))
+			_, err := wt.Users(CurrentUser)
+			So(IsNotFound(err), ShouldBeTrue)
+			So(st.calls, ShouldEqual, 1)
+		})
+		Convey("Retry-After must be honoured", func() {
+			st := &SequenceTransport{codes: []int{429, 200}, header: http.Header{"Retry-After": {"7"}}}
+			wt := New(newTestRetryTransport(st, clock))
+			_, err := wt.Users(CurrentUser)
+			So(err, ShouldBeNil)
+			So(clock.waits, ShouldResemble, []time.Duration{7 * time.Second})
+		})
+		Convey("Total time must be capped", func() {
+			st := &SequenceTransport{codes: []int{429, 200}, header: http.Header{"Retry-After": {"3600"}}}
+			wt := New(newTestRetryTransport(st, clock))
+			_, err := wt.Users(CurrentUser)
+			So(IsRateLimited(err), ShouldBeTrue)
+			So(st.calls, ShouldEqual, 1)
+			So(clock.waits, ShouldBeEmpty)
+		})
+		Convey("Non idempotent requests must not be retried", func() {
+			st := &SequenceTransport{codes: []int{500, 200}, header: http.Header{}}
+			rt := newTestRetryTransport(st, clock)
+			req, _ := http.NewRequest(http.MethodPost, "http://example.com/", strings.NewReader("{}"))
+			resp, err := rt.RoundTrip(req)
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, 500)
+			So(st.calls, ShouldEqual, 1)
+		})
+		Convey("Request body must be resent on retry", func() {
+			st := &SequenceTransport{codes: []int{500, 200}, header: http.Header{}}
+			rt := newTestRetryTransport(st, clock)
+			req, _ := http.NewRequest(http.MethodPut, "http://example.com/", strings.NewReader("{}"))
+			resp, err := rt.RoundTrip(req)
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, 200)
+			So(st.bodies, ShouldResemble, []string{"{}", "{}"})
+		})
+		Convey("Cancelled context must stop retrying", func() {
+			st := &SequenceTransport{codes: []int{500, 200}, header: http.Header{}}
+			wt := New(newTestRetryTransport(st, clock))
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			_, err := wt.UsersWithContext(ctx, CurrentUser)
+			So(err, ShouldNotBeNil)
+			So(st.calls, ShouldBeLessThanOrEqualTo, 1)
+		})
+	})
+	Convey("Given retry transport with jitter", t, func() {
+		rt := NewRetryTransport(nil)
+		rt.Jitter = func() float64 { return 0.5 }
+		Convey("The delay must stay within the jitter bounds", func() {
+			So(rt.backoff(1), ShouldEqual, 375*time.Millisecond)
+			So(rt.backoff(20), ShouldEqual, 22500*time.Millisecond)
+		})
+	})
+	Convey("Given retry transport literal without clock and jitter", t, func() {
+		st := &SequenceTransport{codes: []int{503, 200}, header: http.Header{}}
+		rt := &RetryTransport{Transport: st, MaxAttempts: 3, BaseDelay: time.Millisecond}
+		Convey("The system clock and jitter must be used", func() {
+			u, err := New(rt).Users(CurrentUser)
+			So(err, ShouldBeNil)
+			So(u.Data.Username, ShouldEqual, "aquilax")
+			So(st.calls, ShouldEqual, 2)
+		})
+	})
+}
@@ -0,0 +1,120 @@
+package wakatime
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"time"
+)
+
+// AllTimeSinceTodayRange contains the range of the all time report
+type AllTimeSinceTodayRange struct {
+	End       Time
+	EndDate   string `json:"end_date"`
+	EndText   string `json:"end_text"`
+	Start     Time
+	StartDate string `json:"start_date"`
+	StartText string `json:"start_text"`
+	Timezone  string
+}
+
+// AllTimeSinceTodayData contains the total time logged since the account was
+// created
+type AllTimeSinceTodayData struct {
+	DailyAverage      float64 `json:"daily_average"`
+	Decimal           string
+	Digital           string
+	IsUpToDate        bool `json:"is_up_to_date"`
+	PercentCalculated int  `json:"percent_calculated"`
+	Range             AllTimeSinceTodayRange
+	Text              string
+	Timeout           int
+	TotalSeconds      float64 `json:"total_seconds"`
+}
+
+// AllTimeSinceToday contains the all time since today report
+type AllTimeSinceToday struct {
+	Data AllTimeSinceTodayData
+}
+
+// StatusBarGrandTotal contains today's total. Unlike SummaryGrandTotal the
+// seconds are fractional.
+type StatusBarGrandTotal struct {
+	Decimal      string
+	Digital      string
+	Hours        int
+	Minutes      int
+	Text         string
+	TotalSeconds float64 `json:"total_seconds"`
+}
+
+// StatusBarItem contains the status bar information about single editor,
+// language, project etc.
+type StatusBarItem struct {
+	Name    string
+	Percent float64
+	Seconds int
+	StatusBarGrandTotal
+}
+
+// StatusBarData contains today's summary as shown in the editor status bars
+type StatusBarData struct {
+	Categories       []StatusBarItem
+	Dependencies     []StatusBarItem
+	Editors          []StatusBarItem
+	GrandTotal       StatusBarGrandTotal `json:"grand_total"`
+	Languages        []StatusBarItem
+	Machines         []StatusBarItem
+	OperatingSystems []StatusBarItem `json:"operating_systems"`
+	Projects         []StatusBarItem
+	Range            SummaryRange
+}
+
+// StatusBar contains the status bar report
+type StatusBar struct {
+	CachedAt        time.Time `json:"cached_at"`
+	Data            StatusBarData
+	HasTeamFeatures bool `json:"has_team_features"`
+}
+
+// AllTimeSinceToday fetches the total time logged since the account was
+// created, optionally limited to single project
+func (wt *WakaTime) AllTimeSinceToday(ctx context.Context, user, project string) (*AllTimeSinceToday, error) {
+	var err error
+	var u *url.URL
+	if u, err = wt.apiURL("users", user, "all_time_since_today"); err != nil {
+		return nil, err
+	}
+	if project != "" {
+		q := u.Query()
+		q.Set("project", project)
+		u.RawQuery = q.Encode()
+	}
+	var content []byte
+	if content, err = wt.fetchURL(ctx, u.String()); err != nil {
+		return nil, err
+	}
+	var at AllTimeSinceToday
+	if err = json.Unmarshal(content, &at); err != nil {
+		return nil, err
+	}
+	return &at, nil
+}
+
+// StatusBarToday fetches today's summary for the user
+func (wt *WakaTime) StatusBarToday(ctx context.Context, user string) (*StatusBar, error) {
+	var err error
+	var u *url.URL
+	if u, err = wt.apiURL("users", user, "status_bar", "today"); err != nil {
+		return nil, err
+	}
+	var content []byte
+	if content, err = wt.fetchURL(ctx, u.String()); err != nil {
+		return nil, err
+	}
+	var sb StatusBar
+	if err = json.Unmarshal(content, &sb); err != nil {
+		return nil, err
+	}
+	return &sb, nil
+}
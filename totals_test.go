@@ -0,0 +1,105 @@
+package wakatime
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const (
+	allTime = `{
+  "data": {
+    "daily_average": 7405.7,
+    "decimal": "123.45",
+    "digital": "123:27",
+    "is_up_to_date": false,
+    "percent_calculated": 87,
+    "range": {
+      "end": "2015-04-28T21:59:59Z",
+      "end_date": "2015-04-28",
+      "end_text": "Today",
+      "start": "2015-04-22T22:00:00Z",
+      "start_date": "2015-04-23",
+      "start_text": "Thu Apr 23rd 2015",
+      "timezone": "Europe/Stockholm"
+    },
+    "text": "123 hrs 27 mins",
+    "timeout": 15,
+    "total_seconds": 444420.5
+  }
+}`
+	statusBar = `{
+  "cached_at": "2015-04-28T07:08:06Z",
+  "data": {
+    "categories": [
+      {"digital": "3:03", "hours": 3, "minutes": 3, "name": "Coding", "percent": 100, "text": "3 hrs 3 mins", "total_seconds": 11165}
+    ],
+    "editors": [
+      {"decimal": "3.05", "digital": "3:03", "hours": 3, "minutes": 3, "name": "Vim", "percent": 100, "seconds": 5, "text": "3 hrs 3 mins", "total_seconds": 11165.273}
+    ],
+    "grand_total": {
+      "decimal": "3.05",
+      "digital": "3:03",
+      "hours": 3,
+      "minutes": 3,
+      "text": "3 hrs 3 mins",
+      "total_seconds": 11165.273
+    },
+    "languages": [],
+    "machines": [
+      {"digital": "3:03", "hours": 3, "minutes": 3, "name": "workstation", "percent": 100, "text": "3 hrs 3 mins", "total_seconds": 11165}
+    ],
+    "operating_systems": [],
+    "projects": [],
+    "range": {
+      "date": "2015-04-28",
+      "end": "2015-04-28T21:59:59Z",
+      "start": "2015-04-27T22:00:00Z",
+      "text": "Tue Apr 28th 2015",
+      "timezone": "Europe/Stockholm"
+    }
+  },
+  "has_team_features": false
+}`
+)
+
+func TestTotals(t *testing.T) {
+	Convey("Given wakatime", t, func() {
+		Convey("All time JSON must be correctly parsed", func() {
+			dt := NewDummyTransport(allTime)
+			wt := New(dt)
+			at, err := wt.AllTimeSinceToday(context.Background(), CurrentUser, "go-wakatime")
+			So(err, ShouldBeNil)
+			So(dt.req.URL.Path, ShouldEqual, "/api/v1/users/current/all_time_since_today")
+			So(dt.req.URL.RawQuery, ShouldEqual, "project=go-wakatime")
+			So(at.Data.Decimal, ShouldEqual, "123.45")
+			So(at.Data.Digital, ShouldEqual, "123:27")
+			So(at.Data.IsUpToDate, ShouldBeFalse)
+			So(at.Data.PercentCalculated, ShouldEqual, 87)
+			So(at.Data.Text, ShouldEqual, "123 hrs 27 mins")
+			So(at.Data.TotalSeconds, ShouldEqual, 444420.5)
+			So(at.Data.Range.StartDate, ShouldEqual, "2015-04-23")
+			So(at.Data.Range.End.Time().UTC().Format(time.RFC3339), ShouldEqual, "2015-04-28T21:59:59Z")
+		})
+		Convey("Status bar JSON must be correctly parsed", func() {
+			dt := NewDummyTransport(statusBar)
+			wt := New(dt)
+			sb, err := wt.StatusBarToday(context.Background(), CurrentUser)
+			So(err, ShouldBeNil)
+			So(dt.req.URL.Path, ShouldEqual, "/api/v1/users/current/status_bar/today")
+			So(sb.CachedAt.Format(time.RFC3339), ShouldEqual, "2015-04-28T07:08:06Z")
+			So(sb.Data.GrandTotal.Text, ShouldEqual, "3 hrs 3 mins")
+			So(sb.Data.GrandTotal.TotalSeconds, ShouldEqual, 11165.273)
+			So(sb.Data.GrandTotal.Decimal, ShouldEqual, "3.05")
+			So(sb.Data.Categories[0].Name, ShouldEqual, "Coding")
+			So(sb.Data.Machines[0].Name, ShouldEqual, "workstation")
+			So(sb.Data.Editors[0].Name, ShouldEqual, "Vim")
+			So(sb.Data.Editors[0].TotalSeconds, ShouldEqual, 11165.273)
+			So(sb.Data.Editors[0].Decimal, ShouldEqual, "3.05")
+			So(sb.Data.Range.Date, ShouldEqual, "2015-04-28")
+			So(sb.Data.Range.Start.Time().UTC().Format(time.RFC3339), ShouldEqual, "2015-04-27T22:00:00Z")
+		})
+	})
+}
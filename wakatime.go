@@ -1,12 +1,17 @@
 package wakatime
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"math"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -38,7 +43,13 @@ type Time time.Time
 
 // WakaTime is the main structure
 type WakaTime struct {
-	client *http.Client
+	client    *http.Client
+	baseURL   string
+	userAgent string
+	timezone  string
+	timeout   time.Duration
+	limiter   *RateLimiter
+	clock     Clock
 }
 
 // DurationsData is single duration segment
@@ -69,18 +80,27 @@ type StatsItem struct {
 
 // HeartbeatItem contains single hartbeat item
 type HeartbeatItem struct {
-	Entity       string
-	Type         string
-	Time         float32
-	Project      string
-	Branch       string
-	Language     string
-	Dependencies string
-	Lines        int
-	Lineno       int
-	Cursorpos    int
-	IsWrite      bool `json:"is_write"`
-	IsDebugging  bool `json:"is_debugging"`
+	ID            string     `json:"id,omitempty"`
+	Entity        string     `json:"entity"`
+	Type          string     `json:"type"`
+	Category      string     `json:"category,omitempty"`
+	Time          float64    `json:"time"`
+	Project       string     `json:"project,omitempty"`
+	Branch        string     `json:"branch,omitempty"`
+	Language      string     `json:"language,omitempty"`
+	Dependencies  []string   `json:"dependencies,omitempty"`
+	Lines         int        `json:"lines,omitempty"`
+	LineAdditions int        `json:"line_additions,omitempty"`
+	LineDeletions int        `json:"line_deletions,omitempty"`
+	Lineno        int        `json:"lineno,omitempty"`
+	Cursorpos     int        `json:"cursorpos,omitempty"`
+	IsWrite       bool       `json:"is_write"`
+	IsDebugging   bool       `json:"is_debugging"`
+	Machine       string     `json:"machine,omitempty"`
+	MachineNameID string     `json:"machine_name_id,omitempty"`
+	UserAgent     string     `json:"user_agent,omitempty"`
+	UserAgentID   string     `json:"user_agent_id,omitempty"`
+	CreatedAt     *time.Time `json:"created_at,omitempty"`
 }
 
 // Heartbeats contains the Heartbeats report
@@ -218,33 +238,60 @@ type Users struct {
 }
 
 // New initializes the library
-func New(rt http.RoundTripper) *WakaTime {
-	return &WakaTime{
-		client: &http.Client{
-			Transport: rt,
-		},
+func New(rt http.RoundTripper, opts ...Option) *WakaTime {
+	wt := &WakaTime{
+		client:    &http.Client{},
+		baseURL:   APIBase,
+		userAgent: getUserAgent(),
+		clock:     systemClock{},
 	}
+	for _, opt := range opts {
+		opt(wt)
+	}
+	if wt.client.Transport == nil {
+		wt.client.Transport = rt
+	}
+	if wt.timeout > 0 {
+		wt.client.Timeout = wt.timeout
+	}
+	return wt
 }
 
 // Durations fetches the durations report
+//
+// Deprecated: use DurationsWithOptions
 func (wt *WakaTime) Durations(user string, date time.Time, project, branches *string) (*Durations, error) {
-	var err error
-	var u *url.URL
-	if u, err = url.Parse(APIBase); err != nil {
-		return nil, err
+	return wt.DurationsWithContext(context.Background(), user, date, project, branches)
+}
+
+// DurationsWithContext fetches the durations report using the provided context
+//
+// Deprecated: use DurationsWithOptions
+func (wt *WakaTime) DurationsWithContext(ctx context.Context, user string, date time.Time, project, branches *string) (*Durations, error) {
+	opts := &DurationsOptions{
+		Date: date,
 	}
-	u.Path += "users/" + user + "/durations"
-	q := u.Query()
-	q.Set("date", date.Format(dateFormat))
 	if project != nil {
-		q.Set("project", *project)
+		opts.Project = *project
 	}
 	if branches != nil {
-		q.Set("branches", *branches)
+		opts.Branches = *branches
+	}
+	return wt.DurationsWithOptions(ctx, user, opts)
+}
+
+// DurationsWithOptions fetches the durations report filtered by opts
+func (wt *WakaTime) DurationsWithOptions(ctx context.Context, user string, opts *DurationsOptions) (*Durations, error) {
+	var err error
+	var u *url.URL
+	if u, err = wt.apiURL("users", user, "durations"); err != nil {
+		return nil, err
 	}
+	q := opts.Values()
+	wt.setTimezone(q)
 	u.RawQuery = q.Encode()
 	var content []byte
-	if content, err = wt.fetchURL(u.String()); err != nil {
+	if content, err = wt.fetchURL(ctx, u.String()); err != nil {
 		return nil, err
 	}
 	var dr Durations
@@ -255,26 +302,37 @@ func (wt *WakaTime) Durations(user string, date time.Time, project, branches *st
 }
 
 // Stats fetches the stats report
+//
+// Deprecated: use StatsWithOptions
 func (wt *WakaTime) Stats(user string, rng Range, timeout *int, writesOnly *bool, project *string) (*Stats, error) {
+	return wt.StatsWithContext(context.Background(), user, rng, timeout, writesOnly, project)
+}
+
+// StatsWithContext fetches the stats report using the provided context
+//
+// Deprecated: use StatsWithOptions
+func (wt *WakaTime) StatsWithContext(ctx context.Context, user string, rng Range, timeout *int, writesOnly *bool, project *string) (*Stats, error) {
+	opts := &StatsOptions{
+		Timeout:    timeout,
+		WritesOnly: writesOnly,
+	}
+	if project != nil {
+		opts.Project = *project
+	}
+	return wt.StatsWithOptions(ctx, user, rng, opts)
+}
+
+// StatsWithOptions fetches the stats report for the range filtered by opts.
+// opts can be nil.
+func (wt *WakaTime) StatsWithOptions(ctx context.Context, user string, rng Range, opts *StatsOptions) (*Stats, error) {
 	var err error
 	var u *url.URL
-	if u, err = url.Parse(APIBase); err != nil {
+	if u, err = wt.apiURL("users", user, "stats", rng.String()); err != nil {
 		return nil, err
 	}
-	u.Path += "users/" + user + "/stats/" + rng.String()
-	q := u.Query()
-	if timeout != nil {
-		q.Set("timeout", strconv.Itoa(*timeout))
-	}
-	if writesOnly != nil {
-		q.Set("writes_only", strconv.FormatBool(*writesOnly))
-	}
-	if project != nil {
-		q.Set("project", *project)
-	}
-	u.RawQuery = q.Encode()
+	u.RawQuery = opts.Values().Encode()
 	var content []byte
-	if content, err = wt.fetchURL(u.String()); err != nil {
+	if content, err = wt.fetchURL(ctx, u.String()); err != nil {
 		return nil, err
 	}
 	var st Stats
@@ -284,26 +342,43 @@ func (wt *WakaTime) Stats(user string, rng Range, timeout *int, writesOnly *bool
 	return &st, nil
 }
 
-// Summaries fetches the summaries report
-func (wt *WakaTime) Summaries(user string, start, date time.Time, project, branches *string) (*Summaries, error) {
-	var err error
-	var u *url.URL
-	if u, err = url.Parse(APIBase); err != nil {
-		return nil, err
+// Summaries fetches the summaries report for the days from start to end
+//
+// Deprecated: use SummariesWithOptions
+func (wt *WakaTime) Summaries(user string, start, end time.Time, project, branches *string) (*Summaries, error) {
+	return wt.SummariesWithContext(context.Background(), user, start, end, project, branches)
+}
+
+// SummariesWithContext fetches the summaries report for the days from start to
+// end using the provided context
+//
+// Deprecated: use SummariesWithOptions
+func (wt *WakaTime) SummariesWithContext(ctx context.Context, user string, start, end time.Time, project, branches *string) (*Summaries, error) {
+	opts := &SummariesOptions{
+		Start: start,
+		End:   end,
 	}
-	u.Path += "users/" + user + "/summaries"
-	q := u.Query()
-	q.Set("start", start.Format(dateFormat))
-	q.Set("end", start.Format(dateFormat))
 	if project != nil {
-		q.Set("project", *project)
+		opts.Project = *project
 	}
 	if branches != nil {
-		q.Set("branches", *branches)
+		opts.Branches = *branches
+	}
+	return wt.SummariesWithOptions(ctx, user, opts)
+}
+
+// SummariesWithOptions fetches the summaries report filtered by opts
+func (wt *WakaTime) SummariesWithOptions(ctx context.Context, user string, opts *SummariesOptions) (*Summaries, error) {
+	var err error
+	var u *url.URL
+	if u, err = wt.apiURL("users", user, "summaries"); err != nil {
+		return nil, err
 	}
+	q := opts.Values()
+	wt.setTimezone(q)
 	u.RawQuery = q.Encode()
 	var content []byte
-	if content, err = wt.fetchURL(u.String()); err != nil {
+	if content, err = wt.fetchURL(ctx, u.String()); err != nil {
 		return nil, err
 	}
 	var sm Summaries
@@ -315,14 +390,18 @@ func (wt *WakaTime) Summaries(user string, start, date time.Time, project, branc
 
 // Users fetches the users report
 func (wt *WakaTime) Users(user string) (*Users, error) {
+	return wt.UsersWithContext(context.Background(), user)
+}
+
+// UsersWithContext fetches the users report using the provided context
+func (wt *WakaTime) UsersWithContext(ctx context.Context, user string) (*Users, error) {
 	var err error
 	var u *url.URL
-	if u, err = url.Parse(APIBase); err != nil {
+	if u, err = wt.apiURL("users", user); err != nil {
 		return nil, err
 	}
-	u.Path += "users/" + user
 	var content []byte
-	if content, err = wt.fetchURL(u.String()); err != nil {
+	if content, err = wt.fetchURL(ctx, u.String()); err != nil {
 		return nil, err
 	}
 	var us Users
@@ -333,18 +412,33 @@ func (wt *WakaTime) Users(user string) (*Users, error) {
 }
 
 // GetHartbeats fetches user's heartbeats sent from plugins for the given day
+//
+// Deprecated: use HeartbeatsWithOptions
 func (wt *WakaTime) GetHartbeats(user string, date time.Time) (*Heartbeats, error) {
+	return wt.GetHartbeatsWithContext(context.Background(), user, date)
+}
+
+// GetHartbeatsWithContext fetches user's heartbeats for the given day using the
+// provided context
+//
+// Deprecated: use HeartbeatsWithOptions
+func (wt *WakaTime) GetHartbeatsWithContext(ctx context.Context, user string, date time.Time) (*Heartbeats, error) {
+	return wt.HeartbeatsWithOptions(ctx, user, &HeartbeatsOptions{Date: date})
+}
+
+// HeartbeatsWithOptions fetches user's heartbeats sent from plugins filtered by
+// opts
+func (wt *WakaTime) HeartbeatsWithOptions(ctx context.Context, user string, opts *HeartbeatsOptions) (*Heartbeats, error) {
 	var err error
 	var u *url.URL
-	if u, err = url.Parse(APIBase); err != nil {
+	if u, err = wt.apiURL("users", user, "heartbeats"); err != nil {
 		return nil, err
 	}
-	u.Path += "users/" + user
-	q := u.Query()
-	q.Set("date", date.Format(dateFormat))
+	q := opts.Values()
+	wt.setTimezone(q)
 	u.RawQuery = q.Encode()
 	var content []byte
-	if content, err = wt.fetchURL(u.String()); err != nil {
+	if content, err = wt.fetchURL(ctx, u.String()); err != nil {
 		return nil, err
 	}
 	var h Heartbeats
@@ -354,34 +448,173 @@ func (wt *WakaTime) GetHartbeats(user string, date time.Time) (*Heartbeats, erro
 	return &h, nil
 }
 
-// UnmarshalJSON unmarshals the Time type
+// UnmarshalJSON unmarshals the Time type. The value can be a Unix timestamp
+// with fractional seconds, a RFC3339 string or null.
 func (t *Time) UnmarshalJSON(data []byte) error {
-	ts, err := strconv.ParseFloat(string(data), 32)
+	s := string(data)
+	if s == "null" {
+		*t = Time{}
+		return nil
+	}
+	if strings.HasPrefix(s, `"`) {
+		var str string
+		if err := json.Unmarshal(data, &str); err != nil {
+			return err
+		}
+		if str == "" {
+			*t = Time{}
+			return nil
+		}
+		tm, err := time.Parse(time.RFC3339Nano, str)
+		if err != nil {
+			return err
+		}
+		*t = Time(tm)
+		return nil
+	}
+	sec, ns, err := parseTimestamp(s)
 	if err != nil {
 		return err
 	}
-	sec := int64(ts)
-	ns := int64((ts - float64(sec)) * float64(time.Second))
-	*t = Time(time.Unix(int64(sec), ns))
+	*t = Time(time.Unix(sec, ns))
 	return nil
 }
 
+// MarshalJSON marshals the Time type as Unix timestamp with fractional seconds
+// or null for the zero time
+func (t Time) MarshalJSON() ([]byte, error) {
+	tm := time.Time(t)
+	if tm.IsZero() {
+		return []byte("null"), nil
+	}
+	sec, ns := tm.Unix(), tm.Nanosecond()
+	sign := ""
+	if sec < 0 {
+		// Unix floors towards the past while the fraction is positive, so
+		// -1.5 is -2 seconds plus half a second
+		sign = "-"
+		if ns > 0 {
+			sec, ns = -(sec + 1), int(time.Second)-ns
+		} else {
+			sec = -sec
+		}
+	}
+	if ns == 0 {
+		return []byte(sign + strconv.FormatInt(sec, 10)), nil
+	}
+	frac := strings.TrimRight(fmt.Sprintf("%09d", ns), "0")
+	return []byte(sign + strconv.FormatInt(sec, 10) + "." + frac), nil
+}
+
+// parseTimestamp parses decimal Unix timestamp without losing precision
+func parseTimestamp(s string) (int64, int64, error) {
+	if strings.ContainsAny(s, "eE") {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+		sec := math.Floor(f)
+		return int64(sec), int64(math.Round((f - sec) * float64(time.Second))), nil
+	}
+	intPart, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, frac = s[:i], s[i+1:]
+	}
+	sec, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	if frac == "" {
+		return sec, 0, nil
+	}
+	if len(frac) > 9 {
+		frac = frac[:9]
+	}
+	frac += strings.Repeat("0", 9-len(frac))
+	ns, err := strconv.ParseInt(frac, 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	if strings.HasPrefix(intPart, "-") {
+		ns = -ns
+	}
+	return sec, ns, nil
+}
+
 // String returns the string representation of Range
 func (r Range) String() string {
 	return string(r)
 }
 
-func (wt *WakaTime) fetchURL(url string) ([]byte, error) {
+// apiURL builds the endpoint URL from the configured base and the escaped path
+// segments
+func (wt *WakaTime) apiURL(segments ...string) (*url.URL, error) {
+	base, err := url.Parse(wt.baseURL)
+	if err != nil {
+		return nil, err
+	}
+	escaped := make([]string, len(segments))
+	for i, s := range segments {
+		switch s {
+		case ".", "..":
+			// PathEscape keeps dots, which would be resolved as dot segments
+			escaped[i] = strings.Repeat("%2E", len(s))
+		default:
+			escaped[i] = url.PathEscape(s)
+		}
+	}
+	return base.Parse(strings.Join(escaped, "/"))
+}
+
+// setTimezone sets the default timezone unless the query already has one
+func (wt *WakaTime) setTimezone(q url.Values) {
+	if wt.timezone != "" && q.Get("timezone") == "" {
+		q.Set("timezone", wt.timezone)
+	}
+}
+
+func (wt *WakaTime) fetchURL(ctx context.Context, url string) ([]byte, error) {
+	return wt.sendURL(ctx, http.MethodGet, url, nil)
+}
+
+// sendURL sends request with the JSON encoded payload and returns the response
+// body. GET requests succeed only with status 200, other methods with any 2xx.
+func (wt *WakaTime) sendURL(ctx context.Context, method, url string, payload interface{}) ([]byte, error) {
 	var err error
+	if wt.limiter != nil {
+		if err = wt.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	var body io.Reader
+	if payload != nil {
+		var b []byte
+		if b, err = json.Marshal(payload); err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(b)
+	}
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, method, url, body); err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", wt.userAgent)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
 	var resp *http.Response
-	if resp, err = wt.client.Get(url); err != nil {
+	if resp, err = wt.client.Do(req); err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP Error: %d", resp.StatusCode)
+	var content []byte
+	if content, err = ioutil.ReadAll(resp.Body); err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK && (method == http.MethodGet || resp.StatusCode/100 != 2) {
+		return nil, newAPIError(url, resp, content)
 	}
-	return ioutil.ReadAll(resp.Body)
+	return content, nil
 }
 
 // Time converts Time to time.Time
@@ -3,6 +3,9 @@ package wakatime
 import (
 	"bufio"
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"io/ioutil"
 	"net/http"
 	"testing"
@@ -185,14 +188,24 @@ const (
   "data": [
     {
       "branch": "master",
+      "category": "coding",
+      "created_at": "2015-06-02T04:03:45Z",
+      "cursorpos": 1204,
+      "dependencies": ["django"],
       "entity": "/home/aquilax/projects/frondate_project/frondate/settings/production.py",
       "id": "2727163e-e614-47cd-8ffc-f3dc3f18bcdc",
       "is_debugging": null,
       "is_write": false,
       "language": "Python",
+      "line_additions": 12,
+      "line_deletions": 3,
+      "lineno": 42,
+      "lines": 118,
+      "machine_name_id": "0d7a3e2c-4c2b-4f0e-9b4a-6b1e2c3d4f5a",
       "project": "frondate_project",
       "time": 1433217822.482732,
-      "type": "file"
+      "type": "file",
+      "user_agent_id": "7c7c51d2-2bd7-4d6e-9a3b-1fd1e5f1c0a1"
     },
     {
       "branch": "master",
@@ -214,13 +227,15 @@ const (
 
 type DummyTransport struct {
 	content string
+	req     *http.Request
 }
 
 func NewDummyTransport(content string) *DummyTransport {
-	return &DummyTransport{content}
+	return &DummyTransport{content: content}
 }
 
 func (dt *DummyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	dt.req = req
 	b := bytes.NewBufferString(dt.content)
 	resp := &http.Response{
 		Status:     "200 OK",
@@ -230,9 +245,17 @@ func (dt *DummyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	return resp, nil
 }
 
+// BlockingTransport never responds and waits for the request context to be done
+type BlockingTransport struct{}
+
+func (bt *BlockingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	<-req.Context().Done()
+	return nil, req.Context().Err()
+}
+
 func TestWakatime(t *testing.T) {
 	Convey("Given wakatime", t, func() {
-		wt := New(NewDummyTransport(users))
+		wt := New(newCassette(t, "users"))
 		Convey("Wakatime must not be nil", func() {
 			So(wt, ShouldNotBeNil)
 			Convey("Users JSON must be correctly parsed", func() {
@@ -263,15 +286,17 @@ func TestWakatime(t *testing.T) {
 		})
 	})
 	Convey("Given wakatime", t, func() {
-		wt := New(NewDummyTransport(summaries))
+		cassette := newCassette(t, "summaries")
+		wt := New(cassette)
 		Convey("Wakatime must not be nil", func() {
 			So(wt, ShouldNotBeNil)
 			Convey("Summaries JSON must be correctly parsed", func() {
-				s, err := wt.Summaries(CurrentUser, time.Now(), time.Now(), nil, nil)
+				s, err := wt.Summaries(CurrentUser, time.Date(2015, 4, 23, 0, 0, 0, 0, time.UTC), time.Date(2015, 4, 24, 0, 0, 0, 0, time.UTC), nil, nil)
 				So(err, ShouldBeNil)
+				So(cassette.Unused(), ShouldBeEmpty)
 				So(s, ShouldNotBeNil)
-				So(s.End.Time().Unix(), ShouldEqual, 1429912832)
-				So(s.Start.Time().Unix(), ShouldEqual, 1429740032)
+				So(s.End.Time().Unix(), ShouldEqual, 1429912799)
+				So(s.Start.Time().Unix(), ShouldEqual, 1429740000)
 				So(len(s.Data), ShouldEqual, 1)
 				sday := s.Data[0]
 
@@ -328,8 +353,8 @@ func TestWakatime(t *testing.T) {
 				// Range
 				So(sday.Range.Date, ShouldEqual, "04/23/2015")
 				So(sday.Range.DateHuman, ShouldEqual, "04/23/2015")
-				So(sday.Range.End.Time().Unix(), ShouldEqual, 1429826432)
-				So(sday.Range.Start.Time().Unix(), ShouldEqual, 1429740032)
+				So(sday.Range.End.Time().Unix(), ShouldEqual, 1429826399)
+				So(sday.Range.Start.Time().Unix(), ShouldEqual, 1429740000)
 				So(sday.Range.Text, ShouldEqual, "04/23/2015")
 				So(sday.Range.Timezone, ShouldEqual, "Europe/Stockholm")
 			})
@@ -337,32 +362,36 @@ func TestWakatime(t *testing.T) {
 	})
 
 	Convey("Given wakatime", t, func() {
-		wt := New(NewDummyTransport(durations))
+		cassette := newCassette(t, "durations")
+		wt := New(cassette)
 		Convey("Wakatime must not be nil", func() {
 			So(wt, ShouldNotBeNil)
 			Convey("Durations JSON must be correctly parsed", func() {
-				d, err := wt.Durations(CurrentUser, time.Now(), nil, nil)
+				d, err := wt.Durations(CurrentUser, time.Date(2015, 4, 26, 0, 0, 0, 0, time.UTC), nil, nil)
 				So(err, ShouldBeNil)
+				So(cassette.Unused(), ShouldBeEmpty)
 				So(d, ShouldNotBeNil)
 				So(len(d.Branches), ShouldEqual, 1)
 				So(d.Branches[0], ShouldEqual, "master")
 				So(len(d.Data), ShouldEqual, 1)
 				So(d.Data[0].Duration, ShouldEqual, 2240.0)
 				So(d.Data[0].Project, ShouldEqual, "go-wakatime")
-				So(d.Data[0].Time.Time().UnixNano(), ShouldEqual, 1430021760000000000)
-				So(d.End.Time().Unix(), ShouldEqual, 1430085632)
-				So(d.Start.Time().Unix(), ShouldEqual, 1429999232)
+				So(d.Data[0].Time.Time().UnixNano(), ShouldEqual, 1430021746422815000)
+				So(d.End.Time().Unix(), ShouldEqual, 1430085599)
+				So(d.Start.Time().Unix(), ShouldEqual, 1429999200)
 			})
 		})
 	})
 
 	Convey("Given wakatime", t, func() {
-		wt := New(NewDummyTransport(stats))
+		cassette := newCassette(t, "stats")
+		wt := New(cassette)
 		Convey("Wakatime must not be nil", func() {
 			So(wt, ShouldNotBeNil)
 			Convey("Stats JSON must be correctly parsed", func() {
 				s, err := wt.Stats(CurrentUser, Last30Days, nil, nil, nil)
 				So(err, ShouldBeNil)
+				So(cassette.Unused(), ShouldBeEmpty)
 				So(s, ShouldNotBeNil)
 				So(s.Data.CreatedAt.Format(time.RFC3339), ShouldEqual, "2015-04-23T04:38:05Z")
 				// Editors
@@ -374,7 +403,7 @@ func TestWakatime(t *testing.T) {
 				So(s.Data.Editors[0].Percent, ShouldEqual, 22.64)
 				So(s.Data.Editors[0].TotalSeconds, ShouldEqual, 11803)
 
-				So(s.Data.End.Time().UnixNano(), ShouldEqual, 1430172032000000000)
+				So(s.Data.End.Time().UnixNano(), ShouldEqual, 1430171999000000000)
 				So(s.Data.HumanReadableDailyAverage, ShouldEqual, "2 hours 3 minutes")
 				So(s.Data.HumanReadableTotal, ShouldEqual, "14 hours 24 minutes")
 				So(s.Data.ID, ShouldEqual, "3e570b91-2540-4c9e-a71a-75b1909188ea")
@@ -412,7 +441,7 @@ func TestWakatime(t *testing.T) {
 				So(s.Data.Projects[0].TotalSeconds, ShouldEqual, 23865)
 
 				So(s.Data.Range, ShouldEqual, Last7Days)
-				So(s.Data.Start.Time().UnixNano(), ShouldEqual, 1429567232000000000)
+				So(s.Data.Start.Time().UnixNano(), ShouldEqual, 1429567200000000000)
 				So(s.Data.Status, ShouldEqual, "ok")
 				So(s.Data.Timeout, ShouldEqual, 15)
 				So(s.Data.Timezone, ShouldEqual, "Europe/Stockholm")
@@ -424,17 +453,199 @@ func TestWakatime(t *testing.T) {
 		})
 	})
 	Convey("Given wakatime", t, func() {
-		wt := New(NewDummyTransport(hartbeat))
+		cassette := newCassette(t, "heartbeats")
+		wt := New(cassette)
 		Convey("Wakatime must not be nil", func() {
 			So(wt, ShouldNotBeNil)
-			Convey("Durations JSON must be correctly parsed", func() {
-				h, err := wt.GetHartbeats(CurrentUser, time.Now())
+			Convey("Heartbeats JSON must be correctly parsed", func() {
+				h, err := wt.GetHartbeats(CurrentUser, time.Date(2015, 6, 2, 0, 0, 0, 0, time.UTC))
 				So(err, ShouldBeNil)
+				So(cassette.Unused(), ShouldBeEmpty)
 				So(h, ShouldNotBeNil)
 				So(len(h.Data), ShouldEqual, 2)
-				So(h.End.Time().UTC().Format(time.RFC3339), ShouldEqual, "2015-06-02T22:00:32Z")
-				So(h.Start.Time().UTC().Format(time.RFC3339), ShouldEqual, "2015-06-01T22:00:32Z")
+				hb := h.Data[0]
+				So(hb.ID, ShouldEqual, "2727163e-e614-47cd-8ffc-f3dc3f18bcdc")
+				So(hb.Branch, ShouldEqual, "master")
+				So(hb.Category, ShouldEqual, "coding")
+				So(hb.CreatedAt.Format(time.RFC3339), ShouldEqual, "2015-06-02T04:03:45Z")
+				So(hb.Cursorpos, ShouldEqual, 1204)
+				So(hb.Dependencies, ShouldResemble, []string{"django"})
+				So(hb.Entity, ShouldEqual, "/home/aquilax/projects/frondate_project/frondate/settings/production.py")
+				So(hb.IsDebugging, ShouldBeFalse)
+				So(hb.IsWrite, ShouldBeFalse)
+				So(hb.Language, ShouldEqual, "Python")
+				So(hb.LineAdditions, ShouldEqual, 12)
+				So(hb.LineDeletions, ShouldEqual, 3)
+				So(hb.Lineno, ShouldEqual, 42)
+				So(hb.Lines, ShouldEqual, 118)
+				So(hb.MachineNameID, ShouldEqual, "0d7a3e2c-4c2b-4f0e-9b4a-6b1e2c3d4f5a")
+				So(hb.Project, ShouldEqual, "frondate_project")
+				So(hb.Time, ShouldEqual, 1433217822.482732)
+				So(hb.Type, ShouldEqual, "file")
+				So(hb.UserAgentID, ShouldEqual, "7c7c51d2-2bd7-4d6e-9a3b-1fd1e5f1c0a1")
+				So(h.Data[1].CreatedAt, ShouldBeNil)
+				So(h.End.Time().UTC().Format(time.RFC3339), ShouldEqual, "2015-06-02T21:59:59Z")
+				So(h.Start.Time().UTC().Format(time.RFC3339), ShouldEqual, "2015-06-01T22:00:00Z")
+			})
+		})
+	})
+}
+
+func TestWakatimeContext(t *testing.T) {
+	Convey("Given wakatime with a transport that never responds", t, func() {
+		wt := New(&BlockingTransport{})
+		Convey("Cancelled context must abort the request", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			calls := map[string]func() error{
+				"Durations": func() error {
+					_, err := wt.DurationsWithContext(ctx, CurrentUser, time.Now(), nil, nil)
+					return err
+				},
+				"Stats": func() error {
+					_, err := wt.StatsWithContext(ctx, CurrentUser, Last7Days, nil, nil, nil)
+					return err
+				},
+				"Summaries": func() error {
+					_, err := wt.SummariesWithContext(ctx, CurrentUser, time.Now(), time.Now(), nil, nil)
+					return err
+				},
+				"Users": func() error {
+					_, err := wt.UsersWithContext(ctx, CurrentUser)
+					return err
+				},
+				"GetHartbeats": func() error {
+					_, err := wt.GetHartbeatsWithContext(ctx, CurrentUser, time.Now())
+					return err
+				},
+			}
+			for _, call := range calls {
+				err := call()
+				So(err, ShouldNotBeNil)
+				So(errors.Is(err, context.Canceled), ShouldBeTrue)
+			}
+		})
+		Convey("Expired deadline must abort the request", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			u, err := wt.UsersWithContext(ctx, CurrentUser)
+			So(u, ShouldBeNil)
+			So(errors.Is(err, context.DeadlineExceeded), ShouldBeTrue)
+		})
+	})
+}
+
+func TestSummariesQuery(t *testing.T) {
+	Convey("Given wakatime", t, func() {
+		dt := NewDummyTransport(summaries)
+		wt := New(dt)
+		start := time.Date(2015, 4, 20, 0, 0, 0, 0, time.UTC)
+		end := time.Date(2015, 4, 23, 0, 0, 0, 0, time.UTC)
+		Convey("Summaries must use both start and end", func() {
+			project := "go-wakatime"
+			_, err := wt.Summaries(CurrentUser, start, end, &project, nil)
+			So(err, ShouldBeNil)
+			So(dt.req.URL.Path, ShouldEqual, "/api/v1/users/current/summaries")
+			So(dt.req.URL.RawQuery, ShouldEqual, "end=04%2F23%2F2015&project=go-wakatime&start=04%2F20%2F2015")
+		})
+		Convey("Named range must replace start and end", func() {
+			_, err := wt.SummariesWithOptions(context.Background(), CurrentUser, &SummariesOptions{
+				Start: start,
+				End:   end,
+				Range: RangeLast7DaysFromYesterday,
 			})
+			So(err, ShouldBeNil)
+			So(dt.req.URL.RawQuery, ShouldEqual, "range=Last+7+Days+from+Yesterday")
+		})
+		Convey("All filters must be encoded", func() {
+			writesOnly := true
+			_, err := wt.SummariesWithOptions(context.Background(), CurrentUser, &SummariesOptions{
+				Range:      RangeLastMonth,
+				Project:    "go-wakatime",
+				Branches:   "master,develop",
+				Timezone:   "Europe/Stockholm",
+				WritesOnly: &writesOnly,
+			})
+			So(err, ShouldBeNil)
+			So(dt.req.URL.RawQuery, ShouldEqual, "branches=master%2Cdevelop&project=go-wakatime&range=Last+Month&timezone=Europe%2FStockholm&writes_only=true")
+		})
+		Convey("Explicit timezone must take precedence over the default", func() {
+			wt := New(dt, WithTimezone("UTC"))
+			_, err := wt.SummariesWithOptions(context.Background(), CurrentUser, &SummariesOptions{Range: RangeToday})
+			So(err, ShouldBeNil)
+			So(dt.req.URL.Query().Get("timezone"), ShouldEqual, "UTC")
+			_, err = wt.SummariesWithOptions(context.Background(), CurrentUser, &SummariesOptions{Range: RangeToday, Timezone: "Europe/Stockholm"})
+			So(err, ShouldBeNil)
+			So(dt.req.URL.Query().Get("timezone"), ShouldEqual, "Europe/Stockholm")
+		})
+	})
+}
+
+func TestTime(t *testing.T) {
+	Convey("Given timestamps", t, func() {
+		Convey("Fractional seconds must be preserved", func() {
+			var tm Time
+			So(json.Unmarshal([]byte("1430021746.422815"), &tm), ShouldBeNil)
+			So(tm.Time().UnixNano(), ShouldEqual, 1430021746422815000)
+			b, err := json.Marshal(tm)
+			So(err, ShouldBeNil)
+			So(string(b), ShouldEqual, "1430021746.422815")
+		})
+		Convey("Whole seconds must be preserved", func() {
+			var tm Time
+			So(json.Unmarshal([]byte("1430085599"), &tm), ShouldBeNil)
+			So(tm.Time().UnixNano(), ShouldEqual, 1430085599000000000)
+			b, _ := json.Marshal(tm)
+			So(string(b), ShouldEqual, "1430085599")
+		})
+		Convey("Times before 1970 must round-trip", func() {
+			for _, ts := range []string{"-1.5", "-0.5", "-3", "-1430021746.422815"} {
+				var tm Time
+				So(json.Unmarshal([]byte(ts), &tm), ShouldBeNil)
+				b, err := json.Marshal(tm)
+				So(err, ShouldBeNil)
+				So(string(b), ShouldEqual, ts)
+			}
+			b, _ := json.Marshal(Time(time.Unix(-1, -5e8)))
+			So(string(b), ShouldEqual, "-1.5")
+		})
+		Convey("Exponent notation must be accepted", func() {
+			var tm Time
+			So(json.Unmarshal([]byte("1.4300855995e9"), &tm), ShouldBeNil)
+			So(tm.Time().UnixNano(), ShouldEqual, 1430085599500000000)
+		})
+		Convey("RFC3339 strings must be accepted", func() {
+			var tm Time
+			So(json.Unmarshal([]byte(`"2015-04-23T04:32:26.5Z"`), &tm), ShouldBeNil)
+			So(tm.Time().UnixNano(), ShouldEqual, 1429763546500000000)
+			b, _ := json.Marshal(tm)
+			So(string(b), ShouldEqual, "1429763546.5")
+		})
+		Convey("Null must be the zero time", func() {
+			tm := Time(time.Now())
+			So(json.Unmarshal([]byte("null"), &tm), ShouldBeNil)
+			So(tm.Time().IsZero(), ShouldBeTrue)
+			b, _ := json.Marshal(tm)
+			So(string(b), ShouldEqual, "null")
+		})
+		Convey("Invalid values must fail", func() {
+			var tm Time
+			So(json.Unmarshal([]byte(`"yesterday"`), &tm), ShouldNotBeNil)
+			So(json.Unmarshal([]byte(`true`), &tm), ShouldNotBeNil)
+		})
+	})
+	Convey("Given parsed report", t, func() {
+		var d Durations
+		So(json.Unmarshal([]byte(durations), &d), ShouldBeNil)
+		Convey("It must round-trip through JSON", func() {
+			b1, err := json.Marshal(d)
+			So(err, ShouldBeNil)
+			var d2 Durations
+			So(json.Unmarshal(b1, &d2), ShouldBeNil)
+			b2, err := json.Marshal(d2)
+			So(err, ShouldBeNil)
+			So(string(b2), ShouldEqual, string(b1))
+			So(d2.Data[0].Time.Time().Equal(d.Data[0].Time.Time()), ShouldBeTrue)
 		})
 	})
 }